@@ -0,0 +1,5 @@
+data "triton_fabric_networks" "all" {}
+
+output "fabric_subnets" {
+  value = { for network in data.triton_fabric_networks.all.networks : "${network.vlan_id}/${network.name}" => network.subnet }
+}
@@ -0,0 +1,7 @@
+data "triton_fabric_vlans" "tenant" {
+  name = "tenant-a-*"
+}
+
+output "tenant_vlan_ids" {
+  value = [for vlan in data.triton_fabric_vlans.tenant.vlans : vlan.vlan_id]
+}
@@ -0,0 +1,13 @@
+resource "triton_firewall_rule" "ssh" {
+  description = "Allow ssh traffic on port tcp/22 to machines with the 'bastion' tag."
+  rule        = "FROM any TO tag \"bastion\" ALLOW tcp PORT 22"
+  enabled     = true
+}
+
+data "triton_firewall_rule_machines" "ssh" {
+  rule_id = triton_firewall_rule.ssh.id
+}
+
+output "ssh_exposed_machines" {
+  value = [for machine in data.triton_firewall_rule_machines.ssh.machines : machine.name]
+}
@@ -0,0 +1,8 @@
+data "triton_firewall_rules" "web" {
+  machine_id = triton_machine.web.id
+  enabled    = true
+}
+
+output "web_firewall_rules" {
+  value = [for rule in data.triton_firewall_rules.web.rules : rule.rule]
+}
@@ -0,0 +1,12 @@
+data "triton_images" "base" {
+  name  = "base-64-*"
+  limit = 3
+
+  tags = {
+    role = "os"
+  }
+}
+
+output "latest_base_images" {
+  value = [for image in data.triton_images.base.images : "${image.name}@${image.version}"]
+}
@@ -0,0 +1,11 @@
+data "triton_network" "private" {
+  name = "My-Fabric-Network"
+}
+
+data "triton_network_ips" "private" {
+  network_id = data.triton_network.private.id
+}
+
+output "reserved_ips" {
+  value = [for ip in data.triton_network_ips.private.ips : ip.ip if ip.reserved]
+}
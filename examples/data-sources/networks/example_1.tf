@@ -0,0 +1,8 @@
+data "triton_networks" "private" {
+  name   = "My-*"
+  public = false
+}
+
+output "private_subnets" {
+  value = { for network in data.triton_networks.private.networks : network.name => network.subnet }
+}
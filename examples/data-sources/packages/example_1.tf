@@ -0,0 +1,10 @@
+data "triton_packages" "app" {
+  min_memory = 4096
+  min_vcpus  = 2
+  smallest   = true
+}
+
+resource "triton_machine" "app" {
+  package = data.triton_packages.app.packages[0].name
+  image   = data.triton_image.base.id
+}
@@ -0,0 +1,8 @@
+data "triton_volume_sizes" "nfs" {
+  type = "tritonnfs"
+}
+
+resource "triton_volume" "smallest" {
+  name = "smallest-volume"
+  size = data.triton_volume_sizes.nfs.sizes[0]
+}
@@ -0,0 +1,19 @@
+resource "triton_firewall_rule" "api" {
+  description = "Allow traffic on ports tcp/443 and tcp/8000-8080 to machines tagged role=api from the office network."
+  action      = "allow"
+  enabled     = true
+
+  from {
+    subnet = "10.1.0.0/16"
+  }
+
+  to {
+    tag   = "role"
+    value = "api"
+  }
+
+  protocol {
+    name  = "tcp"
+    ports = ["443", "8000-8080"]
+  }
+}
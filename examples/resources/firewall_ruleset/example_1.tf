@@ -0,0 +1,13 @@
+resource "triton_firewall_ruleset" "web" {
+  description_prefix = "web: "
+
+  rule {
+    description = "Allow HTTP and HTTPS to the web servers"
+    rule        = "FROM any TO tag \"www\" ALLOW tcp (PORT 80 AND PORT 443)"
+  }
+
+  rule {
+    description = "Allow the web servers to reach the database"
+    rule        = "FROM tag \"www\" TO tag \"db\" ALLOW tcp PORT 5432"
+  }
+}
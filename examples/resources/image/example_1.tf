@@ -0,0 +1,24 @@
+data "triton_image" "base" {
+  name        = "base-64-lts"
+  most_recent = true
+}
+
+resource "triton_machine" "builder" {
+  image         = data.triton_image.base.id
+  package       = "g1.nano"
+  desired_state = "stopped"
+
+  user_script = "pkgin -y install nginx"
+}
+
+resource "triton_image" "golden" {
+  machine_id  = triton_machine.builder.id
+  name        = "nginx-golden"
+  version     = "1.0.0"
+  description = "SmartOS base image with nginx installed"
+  homepage    = "https://example.com/images/nginx-golden"
+
+  tags = {
+    role = "web"
+  }
+}
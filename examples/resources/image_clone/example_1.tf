@@ -0,0 +1,8 @@
+data "triton_image" "partner" {
+  name  = "partner-base"
+  owner = "930896af-bf8c-48d4-885c-6573a94b1853"
+}
+
+resource "triton_image_clone" "partner" {
+  source_image_id = data.triton_image.partner.id
+}
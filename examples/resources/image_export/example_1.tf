@@ -0,0 +1,4 @@
+resource "triton_image_export" "golden" {
+  image_id   = triton_image.golden.id
+  manta_path = "/myaccount/stor/images/"
+}
@@ -0,0 +1,10 @@
+resource "triton_image" "golden" {
+  machine_id = triton_machine.builder.id
+  name       = "nginx-golden"
+  version    = "1.0.0"
+}
+
+resource "triton_image_share" "partner" {
+  image_id   = triton_image.golden.id
+  account_id = "7b3e2f1a-0c9d-4e8b-a6f5-4d3c2b1a0e9f"
+}
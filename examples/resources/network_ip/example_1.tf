@@ -0,0 +1,10 @@
+data "triton_network" "private" {
+  name = "My-Fabric-Network"
+}
+
+# Keep the address of the service from being handed out to other machines,
+# so that it survives the machine being replaced.
+resource "triton_network_ip" "service" {
+  network_id = data.triton_network.private.id
+  ip         = "192.168.128.50"
+}
@@ -0,0 +1,24 @@
+data "triton_image" "base" {
+  name        = "base-64-lts"
+  most_recent = true
+}
+
+resource "triton_machine" "db" {
+  image   = data.triton_image.base.id
+  package = "g1.small"
+}
+
+resource "triton_snapshot" "before_upgrade" {
+  name       = "before-upgrade"
+  machine_id = triton_machine.db.id
+}
+
+# Bump the trigger to roll the machine back to the snapshot again.
+resource "triton_snapshot_rollback" "db" {
+  machine_id    = triton_machine.db.id
+  snapshot_name = triton_snapshot.before_upgrade.name
+
+  triggers = {
+    attempt = "1"
+  }
+}
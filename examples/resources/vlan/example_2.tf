@@ -0,0 +1,22 @@
+resource "triton_vlan" "tenant" {
+  vlan_id     = 100
+  name        = "tenant-a"
+  description = "Tenant A"
+
+  network {
+    name               = "tenant-a-web"
+    subnet             = "10.10.0.0/24"
+    gateway            = "10.10.0.1"
+    provision_start_ip = "10.10.0.10"
+    provision_end_ip   = "10.10.0.250"
+    resolvers          = ["8.8.8.8", "8.8.4.4"]
+  }
+
+  network {
+    name               = "tenant-a-db"
+    subnet             = "10.10.1.0/24"
+    provision_start_ip = "10.10.1.10"
+    provision_end_ip   = "10.10.1.250"
+    internet_nat       = false
+  }
+}
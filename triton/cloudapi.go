@@ -0,0 +1,398 @@
+package triton
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+
+	"github.com/TritonDataCenter/triton-go/client"
+	"github.com/TritonDataCenter/triton-go/compute"
+	"github.com/TritonDataCenter/triton-go/network"
+	"github.com/pkg/errors"
+)
+
+// This file holds calls to CloudAPI endpoints that triton-go does not cover,
+// or covers in a way which does not let the provider express every change.
+// They are made through the client embedded in the triton-go service clients
+// so that they share its configuration and request signing.
+
+// imageAction performs one of the `POST /:login/images/:id?action=...`
+// operations and decodes the response into result, if given.
+func imageAction(ctx context.Context, c *compute.ComputeClient, imageID, action string, params url.Values, body interface{}, result interface{}) error {
+	query := &url.Values{}
+	for k, v := range params {
+		(*query)[k] = v
+	}
+	query.Set("action", action)
+
+	respReader, err := c.Client.ExecuteRequestURIParams(ctx, client.RequestInput{
+		Method: http.MethodPost,
+		Path:   path.Join("/", c.Client.AccountName, "images", imageID),
+		Query:  query,
+		Body:   body,
+	})
+	if respReader != nil {
+		defer respReader.Close()
+	}
+	if err != nil {
+		return errors.Wrapf(err, "unable to %s image", action)
+	}
+
+	if result != nil {
+		if err := json.NewDecoder(respReader).Decode(result); err != nil {
+			return errors.Wrapf(err, "unable to decode %s image response", action)
+		}
+	}
+
+	return nil
+}
+
+// updateImage updates the given fields of an image. Unlike
+// compute.ImagesClient.Update, empty values are sent as-is, which allows
+// optional fields to be cleared.
+func updateImage(ctx context.Context, c *compute.ComputeClient, imageID string, fields map[string]interface{}) (*compute.Image, error) {
+	var result *compute.Image
+	if err := imageAction(ctx, c, imageID, "update", nil, fields, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// shareImage adds an account to the ACL of a private image.
+func shareImage(ctx context.Context, c *compute.ComputeClient, imageID, accountID string) (*compute.Image, error) {
+	var result *compute.Image
+	body := map[string]string{"account": accountID}
+	if err := imageAction(ctx, c, imageID, "share", nil, body, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// unshareImage removes an account from the ACL of a private image.
+func unshareImage(ctx context.Context, c *compute.ComputeClient, imageID, accountID string) (*compute.Image, error) {
+	var result *compute.Image
+	body := map[string]string{"account": accountID}
+	if err := imageAction(ctx, c, imageID, "unshare", nil, body, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// cloneImage copies an image shared with this account into the account,
+// returning the new image.
+func cloneImage(ctx context.Context, c *compute.ComputeClient, imageID string) (*compute.Image, error) {
+	var result *compute.Image
+	if err := imageAction(ctx, c, imageID, "clone", nil, nil, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// exportImage exports an image to the given Manta path. Unlike
+// compute.ImagesClient.Export, the path is actually passed to CloudAPI.
+func exportImage(ctx context.Context, c *compute.ComputeClient, imageID, mantaPath string) (*compute.MantaLocation, error) {
+	params := url.Values{}
+	params.Set("manta_path", mantaPath)
+
+	var result *compute.MantaLocation
+	if err := imageAction(ctx, c, imageID, "export", params, nil, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// networkListEntry is an entry of `GET /:login/networks`. CloudAPI lists
+// network pools alongside networks; a pool has no subnet of its own and
+// lists the networks it allocates addresses from instead.
+type networkListEntry struct {
+	network.Network
+	Networks []string `json:"networks"`
+}
+
+// isPool reports whether the entry is a network pool rather than a network.
+func (e *networkListEntry) isPool() bool {
+	return e.Subnet == "" || len(e.Networks) > 0
+}
+
+// listNetworks lists the networks and network pools available to the
+// account. Unlike network.NetworkClient.List, the members of network pools
+// are kept.
+func listNetworks(ctx context.Context, n *network.NetworkClient) ([]*networkListEntry, error) {
+	respReader, err := n.Client.ExecuteRequestURIParams(ctx, client.RequestInput{
+		Method: http.MethodGet,
+		Path:   path.Join("/", n.Client.AccountName, "networks"),
+	})
+	if respReader != nil {
+		defer respReader.Close()
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list networks")
+	}
+
+	var result []*networkListEntry
+	if err := json.NewDecoder(respReader).Decode(&result); err != nil {
+		return nil, errors.Wrap(err, "unable to decode list networks response")
+	}
+
+	return result, nil
+}
+
+// networkIP is an IP address of a network, as returned by the
+// `/:login/networks/:id/ips` endpoints.
+type networkIP struct {
+	IP            string `json:"ip"`
+	Reserved      bool   `json:"reserved"`
+	Managed       bool   `json:"managed"`
+	OwnerUUID     string `json:"owner_uuid"`
+	BelongsToUUID string `json:"belongs_to_uuid"`
+	BelongsToType string `json:"belongs_to_type"`
+}
+
+// networkIPRequest performs a request against the IPs of a network and
+// decodes the response into result.
+func networkIPRequest(ctx context.Context, n *network.NetworkClient, method string, elems []string, body interface{}, result interface{}) error {
+	respReader, err := n.Client.ExecuteRequestURIParams(ctx, client.RequestInput{
+		Method: method,
+		Path:   path.Join(append([]string{"/", n.Client.AccountName, "networks"}, elems...)...),
+		Body:   body,
+	})
+	if respReader != nil {
+		defer respReader.Close()
+	}
+	if err != nil {
+		return errors.Wrap(err, "unable to access network IPs")
+	}
+
+	if err := json.NewDecoder(respReader).Decode(result); err != nil {
+		return errors.Wrap(err, "unable to decode network IPs response")
+	}
+
+	return nil
+}
+
+// listNetworkIPs lists the IP addresses of a network which are in use or
+// reserved.
+func listNetworkIPs(ctx context.Context, n *network.NetworkClient, networkID string) ([]*networkIP, error) {
+	var result []*networkIP
+	if err := networkIPRequest(ctx, n, http.MethodGet, []string{networkID, "ips"}, nil, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// getNetworkIP returns a single IP address of a network.
+func getNetworkIP(ctx context.Context, n *network.NetworkClient, networkID, ip string) (*networkIP, error) {
+	var result *networkIP
+	if err := networkIPRequest(ctx, n, http.MethodGet, []string{networkID, "ips", ip}, nil, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// updateNetworkIP reserves or releases an IP address of a network. Reserved
+// addresses are never handed out automatically, but can still be requested
+// explicitly when provisioning a machine or adding a NIC.
+func updateNetworkIP(ctx context.Context, n *network.NetworkClient, networkID, ip string, reserved bool) (*networkIP, error) {
+	var result *networkIP
+	body := map[string]bool{"reserved": reserved}
+	if err := networkIPRequest(ctx, n, http.MethodPut, []string{networkID, "ips", ip}, body, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// updateFabric changes a fabric network in place. Only the given fields
+// are sent, which lets them be cleared as well as set.
+func updateFabric(ctx context.Context, n *network.NetworkClient, vlanID int, networkID string, changes map[string]interface{}) (*network.Network, error) {
+	respReader, err := n.Client.ExecuteRequestURIParams(ctx, client.RequestInput{
+		Method: http.MethodPut,
+		Path:   path.Join("/", n.Client.AccountName, "fabrics", "default", "vlans", strconv.Itoa(vlanID), "networks", networkID),
+		Body:   changes,
+	})
+	if respReader != nil {
+		defer respReader.Close()
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to update fabric")
+	}
+
+	var result *network.Network
+	if err := json.NewDecoder(respReader).Decode(&result); err != nil {
+		return nil, errors.Wrap(err, "unable to decode update fabric response")
+	}
+
+	return result, nil
+}
+
+// firewallRule is a firewall rule as CloudAPI returns it, including the
+// `log` flag which network.FirewallRule lacks.
+type firewallRule struct {
+	network.FirewallRule
+	Log bool `json:"log"`
+}
+
+// firewallRuleRequest performs a request against the firewall rules of the
+// account and decodes the response into result.
+func firewallRuleRequest(ctx context.Context, n *network.NetworkClient, method string, elems []string, body interface{}, result interface{}) error {
+	respReader, err := n.Client.ExecuteRequestURIParams(ctx, client.RequestInput{
+		Method: method,
+		Path:   path.Join(append([]string{"/", n.Client.AccountName, "fwrules"}, elems...)...),
+		Body:   body,
+	})
+	if respReader != nil {
+		defer respReader.Close()
+	}
+	if err != nil {
+		return errors.Wrap(err, "unable to access firewall rule")
+	}
+
+	if err := json.NewDecoder(respReader).Decode(result); err != nil {
+		return errors.Wrap(err, "unable to decode firewall rule response")
+	}
+
+	return nil
+}
+
+// listFirewallRules returns the firewall rules of the account, or those which
+// apply to a machine if machineID is set, along with their `log` flags.
+func listFirewallRules(ctx context.Context, n *network.NetworkClient, machineID string) ([]*firewallRule, error) {
+	if machineID == "" {
+		var result []*firewallRule
+		if err := firewallRuleRequest(ctx, n, http.MethodGet, nil, nil, &result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	respReader, err := n.Client.ExecuteRequestURIParams(ctx, client.RequestInput{
+		Method: http.MethodGet,
+		Path:   path.Join("/", n.Client.AccountName, "machines", machineID, "fwrules"),
+	})
+	if respReader != nil {
+		defer respReader.Close()
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list firewall rules of machine")
+	}
+
+	var result []*firewallRule
+	if err := json.NewDecoder(respReader).Decode(&result); err != nil {
+		return nil, errors.Wrap(err, "unable to decode list firewall rules response")
+	}
+
+	return result, nil
+}
+
+// getFirewallRule returns a firewall rule along with its `log` flag.
+func getFirewallRule(ctx context.Context, n *network.NetworkClient, ruleID string) (*firewallRule, error) {
+	var result *firewallRule
+	if err := firewallRuleRequest(ctx, n, http.MethodGet, []string{ruleID}, nil, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// createFirewallRule creates a firewall rule from the given fields, which
+// unlike network.CreateRuleInput can include `log`.
+func createFirewallRule(ctx context.Context, n *network.NetworkClient, fields map[string]interface{}) (*firewallRule, error) {
+	var result *firewallRule
+	if err := firewallRuleRequest(ctx, n, http.MethodPost, nil, fields, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// updateFirewallRule updates the given fields of a firewall rule.
+func updateFirewallRule(ctx context.Context, n *network.NetworkClient, ruleID string, fields map[string]interface{}) (*firewallRule, error) {
+	var result *firewallRule
+	if err := firewallRuleRequest(ctx, n, http.MethodPost, []string{ruleID}, fields, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// addNIC attaches a machine to a network. Unlike
+// compute.InstancesClient.AddNIC, the new NIC can be made the primary NIC of
+// the machine.
+func addNIC(ctx context.Context, c *compute.ComputeClient, machineID string, object compute.NetworkObject, primary bool) (*compute.NIC, error) {
+	body := map[string]interface{}{
+		"network": object,
+	}
+	if primary {
+		body["primary"] = true
+	}
+
+	respReader, err := c.Client.ExecuteRequestURIParams(ctx, client.RequestInput{
+		Method: http.MethodPost,
+		Path:   path.Join("/", c.Client.AccountName, "machines", machineID, "nics"),
+		Body:   body,
+	})
+	if respReader != nil {
+		defer respReader.Close()
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to add NIC to machine")
+	}
+
+	var result *compute.NIC
+	if err := json.NewDecoder(respReader).Decode(&result); err != nil {
+		return nil, errors.Wrap(err, "unable to decode add NIC response")
+	}
+
+	return result, nil
+}
+
+// listVolumeSizes returns the sizes, in MiB, which volumes of the given type
+// can be created with, smallest first. compute.VolumesClient has no call for
+// `GET /:login/volumesizes`.
+func listVolumeSizes(ctx context.Context, c *compute.ComputeClient, volumeType string) ([]int, error) {
+	query := &url.Values{}
+	if volumeType != "" {
+		query.Set("type", volumeType)
+	}
+
+	respReader, err := c.Client.ExecuteRequestURIParams(ctx, client.RequestInput{
+		Method: http.MethodGet,
+		Path:   path.Join("/", c.Client.AccountName, "volumesizes"),
+		Query:  query,
+	})
+	if respReader != nil {
+		defer respReader.Close()
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list volume sizes")
+	}
+
+	var result []struct {
+		Size int    `json:"size"`
+		Type string `json:"type"`
+	}
+	if err := json.NewDecoder(respReader).Decode(&result); err != nil {
+		return nil, errors.Wrap(err, "unable to decode list volume sizes response")
+	}
+
+	sizes := make([]int, 0, len(result))
+	for _, entry := range result {
+		if volumeType == "" || entry.Type == volumeType {
+			sizes = append(sizes, entry.Size)
+		}
+	}
+	sort.Ints(sizes)
+
+	return sizes, nil
+}
@@ -0,0 +1,182 @@
+package triton
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/TritonDataCenter/triton-go/network"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+// filterFabricFunc is a function that is called to filter a Fabric Network
+// from a slice of Fabric Networks based on a predicate.
+type filterFabricFunc func(*network.Network) bool
+
+// dataSourceFabricNetworks returns schema for the Fabric Networks data
+// source.
+func dataSourceFabricNetworks() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceFabricNetworksRead,
+		Schema: map[string]*schema.Schema{
+			"vlan_id": {
+				Description:  "The VLAN to list the Fabric Networks of. All VLANs are searched if not set.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validateVLANIdentifier,
+			},
+			"name": {
+				Description: "The name of the Fabric Networks. Supports the `*` and `?` wildcards.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+
+			"networks": {
+				Description: "The Fabric Networks matching the search criteria.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"vlan_id": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"public": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"fabric": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"description": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"subnet": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"provision_start_ip": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"provision_end_ip": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"gateway": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"resolvers": {
+							Type:     schema.TypeList,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+							Computed: true,
+						},
+						"routes": {
+							Type:     schema.TypeMap,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+							Computed: true,
+						},
+						"internet_nat": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// dataSourceFabricNetworksRead retrieves the Fabric Networks of a single VLAN,
+// or of every VLAN in the current Data Center when no VLAN ID is given, from
+// the Fabrics API and returns the ones matching the name filter.
+func dataSourceFabricNetworksRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+
+	net, err := client.Network()
+	if err != nil {
+		return errors.Wrap(err, "error creating Network client")
+	}
+
+	var vlanIDs []int
+	if vlanID, ok := d.GetOkExists("vlan_id"); ok {
+		vlanIDs = append(vlanIDs, vlanID.(int))
+	} else {
+		log.Printf("[DEBUG] triton_fabric_networks: Reading Fabric VLAN details.")
+		vlans, err := net.Fabrics().ListVLANs(context.Background(), &network.ListVLANsInput{})
+		if err != nil {
+			return errors.Wrap(err, "error retrieving Fabric VLAN details")
+		}
+		for _, vlan := range vlans {
+			vlanIDs = append(vlanIDs, vlan.ID)
+		}
+	}
+
+	result := []map[string]interface{}{}
+	for _, vlanID := range vlanIDs {
+		log.Printf("[DEBUG] triton_fabric_networks: Reading Fabric Network details on VLAN %d", vlanID)
+		fabrics, err := net.Fabrics().List(context.Background(), &network.ListFabricsInput{
+			FabricVLANID: vlanID,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "error retrieving Fabric Network details on VLAN %d", vlanID)
+		}
+
+		if name, ok := d.GetOk("name"); ok {
+			fabrics = filterFabrics(fabrics, func(n *network.Network) bool {
+				return wildcardMatch(name.(string), n.Name)
+			})
+		}
+
+		for _, fabric := range fabrics {
+			result = append(result, map[string]interface{}{
+				"id":                 fabric.Id,
+				"name":               fabric.Name,
+				"vlan_id":            vlanID, // The VLAN ID is not part of the `network.Network` type.
+				"public":             fabric.Public,
+				"fabric":             fabric.Fabric,
+				"description":        fabric.Description,
+				"subnet":             fabric.Subnet,
+				"provision_start_ip": fabric.ProvisioningStartIP,
+				"provision_end_ip":   fabric.ProvisioningEndIP,
+				"gateway":            fabric.Gateway,
+				"resolvers":          fabric.Resolvers,
+				"routes":             fabric.Routes,
+				"internet_nat":       fabric.InternetNAT,
+			})
+		}
+	}
+
+	log.Printf("[DEBUG] triton_fabric_networks: Found %d matching Fabric Networks", len(result))
+
+	d.SetId(time.Now().UTC().String())
+	if err := d.Set("networks", result); err != nil {
+		return errors.Wrap(err, "error setting Fabric Networks")
+	}
+
+	return nil
+}
+
+// filterFabrics iterates over a slice of Fabric Networks, and returns a slice
+// that contains all of the Fabric Networks the predicate returns a value of
+// true for.
+func filterFabrics(fabrics []*network.Network, f filterFabricFunc) (results []*network.Network) {
+	for _, fabric := range fabrics {
+		if f(fabric) {
+			results = append(results, fabric)
+		}
+	}
+	return
+}
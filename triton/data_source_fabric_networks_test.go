@@ -0,0 +1,133 @@
+package triton
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccTritonFabricNetworks_basic(t *testing.T) {
+	fabricName := fmt.Sprintf("acctest-%d", acctest.RandInt())
+	vlanNumber := acctest.RandIntRange(3, 2048)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckTritonFabricDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccTritonFabricNetworks_basic, vlanNumber, fabricName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.triton_fabric_networks.vlan", "networks.#", "1"),
+					resource.TestCheckResourceAttr("data.triton_fabric_networks.vlan", "networks.0.name", fabricName),
+					resource.TestCheckResourceAttr("data.triton_fabric_networks.vlan", "networks.0.subnet", "10.60.0.0/22"),
+					resource.TestCheckTypeSetElemNestedAttrs("data.triton_fabric_networks.all", "networks.*", map[string]string{
+						"name":    fabricName,
+						"vlan_id": fmt.Sprintf("%d", vlanNumber),
+					}),
+				),
+			},
+		},
+	})
+}
+
+func TestFakeTritonFabricNetworks_filters(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+
+	if _, err := testFakeApply(resourceVLAN(), nil, map[string]interface{}{
+		"vlan_id": 100,
+		"name":    "tenant-a",
+	}, meta); err != nil {
+		t.Fatalf("error creating VLAN: %s", err)
+	}
+	for i, name := range []string{"tenant-a-web", "tenant-a-db"} {
+		if _, err := testFakeApply(resourceFabric(), nil, map[string]interface{}{
+			"name":               name,
+			"vlan_id":            100,
+			"subnet":             fmt.Sprintf("10.%d.0.0/24", i),
+			"provision_start_ip": fmt.Sprintf("10.%d.0.5", i),
+			"provision_end_ip":   fmt.Sprintf("10.%d.0.250", i),
+		}, meta); err != nil {
+			t.Fatalf("error creating fabric: %s", err)
+		}
+	}
+
+	r := dataSourceFabricNetworks()
+	cases := []struct {
+		name   string
+		config map[string]interface{}
+		names  []string
+		vlans  []int
+	}{
+		{
+			name:   "all VLANs",
+			config: map[string]interface{}{},
+			names:  []string{"My-Fabric-Network", "tenant-a-db", "tenant-a-web"},
+			vlans:  []int{fakeFabricVLANID, 100, 100},
+		},
+		{
+			name:   "one VLAN",
+			config: map[string]interface{}{"vlan_id": 100},
+			names:  []string{"tenant-a-db", "tenant-a-web"},
+			vlans:  []int{100, 100},
+		},
+		{
+			name:   "wildcard name across VLANs",
+			config: map[string]interface{}{"name": "*-web"},
+			names:  []string{"tenant-a-web"},
+			vlans:  []int{100},
+		},
+		{
+			name:   "no match",
+			config: map[string]interface{}{"vlan_id": fakeFabricVLANID, "name": "tenant-*"},
+			names:  []string{},
+			vlans:  []int{},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			state, err := testFakeRead(r, tc.config, meta)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			networks := r.Data(state).Get("networks").([]interface{})
+			if len(networks) != len(tc.names) {
+				t.Fatalf("expected %d networks, got %d: %v", len(tc.names), len(networks), networks)
+			}
+			for i, name := range tc.names {
+				n := networks[i].(map[string]interface{})
+				if n["name"] != name || n["vlan_id"] != tc.vlans[i] || n["fabric"] != true {
+					t.Errorf("expected network %q on VLAN %d at %d, got %v", name, tc.vlans[i], i, n)
+				}
+			}
+		})
+	}
+}
+
+var testAccTritonFabricNetworks_basic = `
+resource "triton_vlan" "test" {
+  vlan_id     = %d
+  name        = "%s"
+}
+
+resource "triton_fabric" "test" {
+  name               = triton_vlan.test.name
+  vlan_id            = triton_vlan.test.vlan_id
+  subnet             = "10.60.0.0/22"
+  gateway            = "10.60.0.1"
+  provision_start_ip = "10.60.0.5"
+  provision_end_ip   = "10.60.3.250"
+}
+
+data "triton_fabric_networks" "vlan" {
+  vlan_id = triton_fabric.test.vlan_id
+}
+
+data "triton_fabric_networks" "all" {
+  name = triton_fabric.test.name
+}
+`
@@ -0,0 +1,100 @@
+package triton
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/TritonDataCenter/triton-go/network"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+// dataSourceFabricVLANs returns schema for the Fabric VLANs data source.
+func dataSourceFabricVLANs() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceFabricVLANsRead,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Description: "The name of the Fabric VLANs. Supports the `*` and `?` wildcards.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"description": {
+				Description: "The description of the Fabric VLANs. Supports the `*` and `?` wildcards.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+
+			"vlans": {
+				Description: "The Fabric VLANs matching the search criteria.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"vlan_id": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"description": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// dataSourceFabricVLANsRead retrieves all the Fabric VLANs which are
+// available in the current Data Center from the Fabrics API, and returns the
+// ones matching the name and description filters. Unlike the Fabric VLAN data
+// source, any number of matches is fine.
+func dataSourceFabricVLANsRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+
+	net, err := client.Network()
+	if err != nil {
+		return errors.Wrap(err, "error creating Network client")
+	}
+
+	log.Printf("[DEBUG] triton_fabric_vlans: Reading Fabric VLAN details.")
+	vlans, err := net.Fabrics().ListVLANs(context.Background(), &network.ListVLANsInput{})
+	if err != nil {
+		return errors.Wrap(err, "error retrieving Fabric VLAN details")
+	}
+
+	if vlanName, ok := d.GetOk("name"); ok {
+		vlans = filterVLANs(vlans, func(v *network.FabricVLAN) bool {
+			return wildcardMatch(vlanName.(string), v.Name)
+		})
+	}
+	if vlanDesc, ok := d.GetOk("description"); ok {
+		vlans = filterVLANs(vlans, func(v *network.FabricVLAN) bool {
+			return wildcardMatch(vlanDesc.(string), v.Description)
+		})
+	}
+
+	log.Printf("[DEBUG] triton_fabric_vlans: Found %d matching Fabric VLANs", len(vlans))
+
+	result := make([]map[string]interface{}, 0, len(vlans))
+	for _, vlan := range vlans {
+		result = append(result, map[string]interface{}{
+			"vlan_id":     vlan.ID,
+			"name":        vlan.Name,
+			"description": vlan.Description,
+		})
+	}
+
+	d.SetId(time.Now().UTC().String())
+	if err := d.Set("vlans", result); err != nil {
+		return errors.Wrap(err, "error setting Fabric VLANs")
+	}
+
+	return nil
+}
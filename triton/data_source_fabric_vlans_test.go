@@ -0,0 +1,109 @@
+package triton
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccTritonFabricVLANs_basic(t *testing.T) {
+	vlanID := acctest.RandIntRange(3, 2048)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckTritonVLANDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccTritonFabricVLANs_basic, vlanID, vlanID, vlanID),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.triton_fabric_vlans.test", "vlans.#", "2"),
+					resource.TestCheckTypeSetElemNestedAttrs("data.triton_fabric_vlans.test", "vlans.*", map[string]string{
+						"vlan_id": fmt.Sprintf("%d", vlanID),
+					}),
+				),
+			},
+		},
+	})
+}
+
+func TestFakeTritonFabricVLANs_filters(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+
+	for _, vlan := range []map[string]interface{}{
+		{"vlan_id": 100, "name": "tenant-a-web", "description": "Tenant A"},
+		{"vlan_id": 101, "name": "tenant-a-db", "description": "Tenant A"},
+		{"vlan_id": 200, "name": "tenant-b-web", "description": "Tenant B"},
+	} {
+		if _, err := testFakeApply(resourceVLAN(), nil, vlan, meta); err != nil {
+			t.Fatalf("error creating VLAN: %s", err)
+		}
+	}
+
+	r := dataSourceFabricVLANs()
+	cases := []struct {
+		name   string
+		config map[string]interface{}
+		ids    []int
+	}{
+		{
+			name:   "all",
+			config: map[string]interface{}{},
+			ids:    []int{fakeFabricVLANID, 100, 101, 200},
+		},
+		{
+			name:   "wildcard name",
+			config: map[string]interface{}{"name": "tenant-a-*"},
+			ids:    []int{100, 101},
+		},
+		{
+			name:   "name and description",
+			config: map[string]interface{}{"name": "*-web", "description": "Tenant B"},
+			ids:    []int{200},
+		},
+		{
+			name:   "no match",
+			config: map[string]interface{}{"name": "tenant-c-*"},
+			ids:    []int{},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			state, err := testFakeRead(r, tc.config, meta)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			vlans := r.Data(state).Get("vlans").([]interface{})
+			if len(vlans) != len(tc.ids) {
+				t.Fatalf("expected %d VLANs, got %d: %v", len(tc.ids), len(vlans), vlans)
+			}
+			for i, id := range tc.ids {
+				if vlan := vlans[i].(map[string]interface{}); vlan["vlan_id"] != id {
+					t.Errorf("expected VLAN %d at %d, got %v", id, i, vlan)
+				}
+			}
+		})
+	}
+}
+
+var testAccTritonFabricVLANs_basic = `
+resource "triton_vlan" "test_1" {
+  name        = "Test-Fabric-VLAN-%d"
+  vlan_id     = %d
+}
+
+resource "triton_vlan" "test_2" {
+  name        = "Test-Fabric-VLAN-%d-2"
+  vlan_id     = triton_vlan.test_1.vlan_id + 1
+}
+
+data "triton_fabric_vlans" "test" {
+  name = "${triton_vlan.test_1.name}*"
+
+  depends_on = [triton_vlan.test_2]
+}
+`
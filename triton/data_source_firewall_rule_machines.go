@@ -0,0 +1,110 @@
+package triton
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/TritonDataCenter/triton-go/network"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+// dataSourceFirewallRuleMachines returns schema for the Firewall Rule
+// Machines data source.
+func dataSourceFirewallRuleMachines() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceFirewallRuleMachinesRead,
+		Schema: map[string]*schema.Schema{
+			"rule_id": {
+				Description: "The ID of the Firewall Rule.",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+
+			"machines": {
+				Description: "The machines the Firewall Rule applies to.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"state": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"primary_ip": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"ips": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"tags": {
+							Type:     schema.TypeMap,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// dataSourceFirewallRuleMachinesRead retrieves the machines a Firewall Rule
+// applies to.
+func dataSourceFirewallRuleMachinesRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+
+	net, err := client.Network()
+	if err != nil {
+		return errors.Wrap(err, "error creating Network client")
+	}
+
+	ruleID := d.Get("rule_id").(string)
+
+	log.Printf("[DEBUG] triton_firewall_rule_machines: Reading machines of Firewall Rule %q.", ruleID)
+	machines, err := net.Firewall().ListRuleMachines(context.Background(), &network.ListRuleMachinesInput{
+		ID: ruleID,
+	})
+	if err != nil {
+		return errors.Wrap(err, "error retrieving Firewall Rule machines")
+	}
+
+	log.Printf("[DEBUG] triton_firewall_rule_machines: Found %d machines", len(machines))
+
+	result := make([]map[string]interface{}, 0, len(machines))
+	for _, machine := range machines {
+		// Tags can be strings, numbers or booleans.
+		tags := make(map[string]interface{}, len(machine.Tags))
+		for k, v := range machine.Tags {
+			tags[k] = fmt.Sprint(v)
+		}
+
+		result = append(result, map[string]interface{}{
+			"id":         machine.ID,
+			"name":       machine.Name,
+			"state":      machine.State,
+			"primary_ip": machine.PrimaryIP,
+			"ips":        machine.IPs,
+			"tags":       tags,
+		})
+	}
+
+	d.SetId(time.Now().UTC().String())
+	if err := d.Set("machines", result); err != nil {
+		return errors.Wrap(err, "error setting Firewall Rule machines")
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package triton
+
+import (
+	"testing"
+
+	"github.com/TritonDataCenter/triton-go/network"
+)
+
+func TestFakeTritonFirewallRuleMachines_basic(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+
+	var ids []string
+	for _, tags := range []map[string]interface{}{
+		{"role": "www"},
+		{"role": "db"},
+		{"role": "www", "env": "prod"},
+	} {
+		state, err := testFakeApply(resourceMachine(), nil, map[string]interface{}{
+			"package": "g1.nano",
+			"image":   fakeImageBase64LTSID,
+			"tags":    tags,
+		}, meta)
+		if err != nil {
+			t.Fatalf("error creating machine: %s", err)
+		}
+		ids = append(ids, state.ID)
+	}
+
+	f.rules["web"] = &firewallRule{FirewallRule: network.FirewallRule{
+		ID:      "web",
+		Rule:    `FROM any TO tag "role" = "www" ALLOW tcp PORT 80`,
+		Enabled: true,
+	}}
+
+	r := dataSourceFirewallRuleMachines()
+	state, err := testFakeRead(r, map[string]interface{}{"rule_id": "web"}, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	machines := r.Data(state).Get("machines").([]interface{})
+	if len(machines) != 2 {
+		t.Fatalf("expected 2 machines, got %d: %v", len(machines), machines)
+	}
+	for i, id := range []string{ids[0], ids[2]} {
+		m := machines[i].(map[string]interface{})
+		if m["id"] != id {
+			t.Errorf("expected machine %s at %d, got %v", id, i, m)
+		}
+		if tags := m["tags"].(map[string]interface{}); tags["role"] != "www" {
+			t.Errorf("expected the tags of machine %s, got %v", id, tags)
+		}
+	}
+
+	if _, err := testFakeRead(r, map[string]interface{}{"rule_id": "missing"}, meta); err == nil {
+		t.Fatal("expected an unknown rule to fail")
+	}
+}
@@ -0,0 +1,149 @@
+package triton
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+// filterFirewallRuleFunc is a function that is called to filter a Firewall
+// Rule from a slice of Firewall Rules based on a predicate.
+type filterFirewallRuleFunc func(*firewallRule) bool
+
+// dataSourceFirewallRules returns schema for the Firewall Rules data source.
+func dataSourceFirewallRules() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceFirewallRulesRead,
+		Schema: map[string]*schema.Schema{
+			"machine_id": {
+				Description: "Only list the Firewall Rules which apply to this machine.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"enabled": {
+				Description: "Whether the Firewall Rules are enabled.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+			},
+			"global": {
+				Description: "Whether the Firewall Rules are global.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+			},
+			"description": {
+				Description: "The description of the Firewall Rules. Supports the `*` and `?` wildcards.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+
+			"rules": {
+				Description: "The Firewall Rules matching the search criteria.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"rule": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"enabled": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"global": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"description": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"log": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// dataSourceFirewallRulesRead retrieves the Firewall Rules of the account, or
+// the ones which apply to a machine, and returns the ones matching the
+// enabled, global and description filters.
+func dataSourceFirewallRulesRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+
+	net, err := client.Network()
+	if err != nil {
+		return errors.Wrap(err, "error creating Network client")
+	}
+
+	machineID := d.Get("machine_id").(string)
+	if machineID != "" {
+		log.Printf("[DEBUG] triton_firewall_rules: Reading Firewall Rules of machine %q.", machineID)
+	} else {
+		log.Printf("[DEBUG] triton_firewall_rules: Reading Firewall Rules.")
+	}
+	rules, err := listFirewallRules(context.Background(), net, machineID)
+	if err != nil {
+		return errors.Wrap(err, "error retrieving Firewall Rules")
+	}
+
+	if enabled, ok := d.GetOkExists("enabled"); ok {
+		rules = filterFirewallRules(rules, func(rule *firewallRule) bool {
+			return rule.Enabled == enabled.(bool)
+		})
+	}
+	if global, ok := d.GetOkExists("global"); ok {
+		rules = filterFirewallRules(rules, func(rule *firewallRule) bool {
+			return rule.Global == global.(bool)
+		})
+	}
+	if ruleDesc, ok := d.GetOk("description"); ok {
+		rules = filterFirewallRules(rules, func(rule *firewallRule) bool {
+			return wildcardMatch(ruleDesc.(string), rule.Description)
+		})
+	}
+
+	log.Printf("[DEBUG] triton_firewall_rules: Found %d matching Firewall Rules", len(rules))
+
+	result := make([]map[string]interface{}, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, map[string]interface{}{
+			"id":          rule.ID,
+			"rule":        rule.Rule,
+			"enabled":     rule.Enabled,
+			"global":      rule.Global,
+			"description": rule.Description,
+			"log":         rule.Log,
+		})
+	}
+
+	d.SetId(time.Now().UTC().String())
+	if err := d.Set("rules", result); err != nil {
+		return errors.Wrap(err, "error setting Firewall Rules")
+	}
+
+	return nil
+}
+
+// filterFirewallRules iterates over a slice of Firewall Rules, and returns a
+// slice that contains all of the Firewall Rules the predicate returns a value
+// of true for.
+func filterFirewallRules(rules []*firewallRule, f filterFirewallRuleFunc) (results []*firewallRule) {
+	for _, rule := range rules {
+		if f(rule) {
+			results = append(results, rule)
+		}
+	}
+	return
+}
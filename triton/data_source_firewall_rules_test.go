@@ -0,0 +1,113 @@
+package triton
+
+import (
+	"testing"
+
+	"github.com/TritonDataCenter/triton-go/network"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccTritonFirewallRules_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckTritonFirewallRuleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTritonFirewallRules_basic,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.triton_firewall_rules.test", "rules.#", "1"),
+					resource.TestCheckResourceAttrPair("data.triton_firewall_rules.test", "rules.0.id", "triton_firewall_rule.test", "id"),
+				),
+			},
+		},
+	})
+}
+
+func TestFakeTritonFirewallRules_filters(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+
+	machine, err := testFakeApply(resourceMachine(), nil, map[string]interface{}{
+		"name":    "fake-machine",
+		"package": "g1.nano",
+		"image":   fakeImageBase64LTSID,
+		"tags":    map[string]interface{}{"role": "www"},
+	}, meta)
+	if err != nil {
+		t.Fatalf("error creating machine: %s", err)
+	}
+
+	for _, rule := range []network.FirewallRule{
+		{ID: "a-web", Rule: `FROM any TO tag "role" = "www" ALLOW tcp PORT 80`, Description: "web", Enabled: true},
+		{ID: "b-db", Rule: `FROM any TO tag "role" = "db" ALLOW tcp PORT 5432`, Description: "db", Enabled: true},
+		{ID: "c-ssh", Rule: `FROM any TO all vms ALLOW tcp PORT 22`, Description: "web ssh", Enabled: false},
+		{ID: "d-global", Rule: `FROM any TO all vms ALLOW icmp TYPE 8 CODE 0`, Description: "ping", Enabled: true, Global: true},
+	} {
+		f.rules[rule.ID] = &firewallRule{FirewallRule: rule, Log: rule.ID == "a-web"}
+	}
+
+	r := dataSourceFirewallRules()
+	cases := []struct {
+		name   string
+		config map[string]interface{}
+		ids    []string
+	}{
+		{
+			name:   "all",
+			config: map[string]interface{}{},
+			ids:    []string{"a-web", "b-db", "c-ssh", "d-global"},
+		},
+		{
+			name:   "disabled",
+			config: map[string]interface{}{"enabled": false},
+			ids:    []string{"c-ssh"},
+		},
+		{
+			name:   "not global",
+			config: map[string]interface{}{"global": false, "enabled": true},
+			ids:    []string{"a-web", "b-db"},
+		},
+		{
+			name:   "wildcard description",
+			config: map[string]interface{}{"description": "web*"},
+			ids:    []string{"a-web", "c-ssh"},
+		},
+		{
+			name:   "machine",
+			config: map[string]interface{}{"machine_id": machine.ID, "enabled": true},
+			ids:    []string{"a-web", "d-global"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			state, err := testFakeRead(r, tc.config, meta)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rules := r.Data(state).Get("rules").([]interface{})
+			if len(rules) != len(tc.ids) {
+				t.Fatalf("expected %d rules, got %d: %v", len(tc.ids), len(rules), rules)
+			}
+			for i, id := range tc.ids {
+				if rule := rules[i].(map[string]interface{}); rule["id"] != id || rule["log"] != (id == "a-web") {
+					t.Errorf("expected rule %s at %d, logged only if it is a-web, got %v", id, i, rule)
+				}
+			}
+		})
+	}
+}
+
+var testAccTritonFirewallRules_basic = `
+resource "triton_firewall_rule" "test" {
+	rule = "FROM any TO tag \"www\" ALLOW tcp PORT 80"
+	enabled = false
+	description = "Test-Firewall-Rule-Lookup"
+}
+
+data "triton_firewall_rules" "test" {
+	description = triton_firewall_rule.test.description
+	enabled = false
+}
+`
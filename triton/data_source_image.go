@@ -2,8 +2,11 @@ package triton
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"regexp"
+	"time"
 
 	"github.com/TritonDataCenter/triton-go/compute"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -17,6 +20,7 @@ func dataSourceImage() *schema.Resource {
 				Description: "The name of the image.",
 				Type:        schema.TypeString,
 				Optional:    true,
+				Computed:    true,
 				ForceNew:    true,
 			},
 
@@ -24,6 +28,7 @@ func dataSourceImage() *schema.Resource {
 				Description: "The underlying operating system for the image.",
 				Type:        schema.TypeString,
 				Optional:    true,
+				Computed:    true,
 				ForceNew:    true,
 			},
 
@@ -31,6 +36,7 @@ func dataSourceImage() *schema.Resource {
 				Description: "The version for the image.",
 				Type:        schema.TypeString,
 				Optional:    true,
+				Computed:    true,
 				ForceNew:    true,
 			},
 
@@ -38,6 +44,7 @@ func dataSourceImage() *schema.Resource {
 				Description: "Whether to return public as well as private images",
 				Type:        schema.TypeBool,
 				Optional:    true,
+				Computed:    true,
 				ForceNew:    true,
 			},
 
@@ -45,6 +52,7 @@ func dataSourceImage() *schema.Resource {
 				Description: "The state of the image. By default, only `active` images are shown. Must be one of: `active`, `unactivated`, `disabled`, `creating`, `failed` or `all`, though the default is sufficient in almost every case.",
 				Type:        schema.TypeString,
 				Optional:    true,
+				Computed:    true,
 				ForceNew:    true,
 			},
 
@@ -52,6 +60,7 @@ func dataSourceImage() *schema.Resource {
 				Description: "The UUID of the account which owns the image.",
 				Type:        schema.TypeString,
 				Optional:    true,
+				Computed:    true,
 				ForceNew:    true,
 			},
 
@@ -59,9 +68,27 @@ func dataSourceImage() *schema.Resource {
 				Description: "The image type. Must be one of: `zone-dataset`, `lx-dataset`, `zvol`, `docker` or `other`.",
 				Type:        schema.TypeString,
 				Optional:    true,
+				Computed:    true,
 				ForceNew:    true,
 			},
 
+			"name_regex": {
+				Description:  "A regular expression the name of the image must match.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validateRegexp,
+			},
+
+			"tags": {
+				Description: "Tags, all of which the image must have with the same value. When not set, the tags of the image found.",
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+
 			"most_recent": {
 				Description: "If more than one result is returned, use the most recent Image.",
 				Type:        schema.TypeBool,
@@ -69,6 +96,79 @@ func dataSourceImage() *schema.Resource {
 				Default:     false,
 				ForceNew:    true,
 			},
+
+			// Computed parameters
+			"description": {
+				Description: "A short description of the image.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+
+			"homepage": {
+				Description: "Homepage URL where users can find more information about the image.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+
+			"eula": {
+				Description: "URL of the End User License Agreement (EULA) for the image.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+
+			"acl": {
+				Description: "Account UUIDs given access to this private image.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+
+			"requirements": {
+				Description: "Requirements for provisioning a machine with the image, e.g. `min_ram`. Values which are not strings are JSON encoded.",
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+
+			"min_ram": {
+				Description: "The minimum RAM (MiB) a machine provisioned with the image needs, or 0 when the image has no such requirement.",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+
+			"max_ram": {
+				Description: "The maximum RAM (MiB) a machine provisioned with the image may have, or 0 when the image has no such requirement.",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+
+			"files": {
+				Description: "The files making up the image.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"compression": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"sha1": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"size": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+
+			"published_at": {
+				Description: "When the image was published.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
 		},
 	}
 }
@@ -112,6 +212,23 @@ func dataSourceImageRead(d *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 
+	if nameRegex, hasNameRegex := d.GetOk("name_regex"); hasNameRegex {
+		r := regexp.MustCompile(nameRegex.(string))
+		images = filterImages(images, func(image *compute.Image) bool {
+			return r.MatchString(image.Name)
+		})
+	}
+	if tags, hasTags := d.GetOk("tags"); hasTags {
+		images = filterImages(images, func(image *compute.Image) bool {
+			for k, v := range tags.(map[string]interface{}) {
+				if value, found := image.Tags[k]; !found || value != v.(string) {
+					return false
+				}
+			}
+			return true
+		})
+	}
+
 	var image *compute.Image
 	if len(images) == 0 {
 		return fmt.Errorf("your query returned no results, please change " +
@@ -132,5 +249,83 @@ func dataSourceImageRead(d *schema.ResourceData, meta interface{}) error {
 	}
 
 	d.SetId(image.ID)
+
+	// The filters CloudAPI matches exactly always agree with the image found.
+	d.Set("name", image.Name)
+	d.Set("os", image.OS)
+	d.Set("version", image.Version)
+	d.Set("owner", image.Owner)
+	d.Set("type", image.Type)
+
+	// The remaining filters are not a property of the image (e.g. a `state`
+	// of "all"), so they are only reported when not used in the query.
+	if _, hasState := d.GetOk("state"); !hasState {
+		d.Set("state", image.State)
+	}
+	if config := d.GetRawConfig(); config.IsNull() || config.GetAttr("public").IsNull() {
+		d.Set("public", image.Public)
+	}
+	if _, hasTags := d.GetOk("tags"); !hasTags {
+		d.Set("tags", image.Tags)
+	}
+
+	d.Set("description", image.Description)
+	d.Set("homepage", image.Homepage)
+	d.Set("eula", image.EULA)
+	d.Set("acl", image.ACL)
+	d.Set("requirements", flattenImageRequirements(image.Requirements))
+	d.Set("min_ram", imageRequirementInt(image.Requirements, "min_ram"))
+	d.Set("max_ram", imageRequirementInt(image.Requirements, "max_ram"))
+	d.Set("published_at", image.PublishedAt.Format(time.RFC3339))
+
+	files := make([]map[string]interface{}, 0, len(image.Files))
+	for _, file := range image.Files {
+		files = append(files, map[string]interface{}{
+			"compression": file.Compression,
+			"sha1":        file.SHA1,
+			"size":        file.Size,
+		})
+	}
+	d.Set("files", files)
+
 	return nil
 }
+
+// flattenImageRequirements converts the free-form requirements of an image
+// into a map of strings, JSON encoding any value which is not a string.
+func flattenImageRequirements(requirements map[string]interface{}) map[string]string {
+	result := make(map[string]string, len(requirements))
+	for k, v := range requirements {
+		if s, ok := v.(string); ok {
+			result[k] = s
+			continue
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			log.Printf("[WARN] triton_image: Unable to encode requirement %q: %s", k, err)
+			continue
+		}
+		result[k] = string(b)
+	}
+	return result
+}
+
+// imageRequirementInt returns a numeric requirement of an image, or 0 when the
+// image does not have it.
+func imageRequirementInt(requirements map[string]interface{}, key string) int {
+	switch v := requirements[key].(type) {
+	case float64:
+		return int(v)
+	case json.Number:
+		n, err := v.Int64()
+		if err != nil {
+			log.Printf("[WARN] triton_image: Unable to decode requirement %q: %s", key, err)
+		}
+		return int(n)
+	case nil:
+		return 0
+	default:
+		log.Printf("[WARN] triton_image: Ignoring requirement %q of unexpected type %T", key, v)
+		return 0
+	}
+}
@@ -94,3 +94,67 @@ data "triton_image" "base" {
 	most_recent = true
 }
 `
+
+func TestFakeTritonImage_attributes(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+	r := dataSourceImage()
+
+	state, err := testFakeRead(r, map[string]interface{}{
+		"name":        "base-64-lts",
+		"most_recent": true,
+	}, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range map[string]string{
+		"id":                  fakeImageBase64LTSID,
+		"version":             "24.4.0",
+		"os":                  "smartos",
+		"type":                "zone-dataset",
+		"state":               "active",
+		"public":              "true",
+		"owner":               fakeImageOwner,
+		"tags.role":           "os",
+		"published_at":        "2025-01-15T00:00:00Z",
+		"min_ram":             "0",
+		"files.#":             "1",
+		"files.0.compression": "gzip",
+		"files.0.size":        "136478213",
+		"homepage":            "https://docs.tritondatacenter.com/public-cloud/instances/infrastructure/images",
+	} {
+		if state.Attributes[k] != v {
+			t.Errorf("expected %s to be %q, got %q", k, v, state.Attributes[k])
+		}
+	}
+
+	state, err = testFakeRead(r, map[string]interface{}{
+		"name_regex": "^ubuntu-\\d+\\.04$",
+	}, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.ID != fakeImageUbuntuID || state.Attributes["requirements.min_ram"] != "1024" {
+		t.Fatalf("unexpected image found by name_regex: %#v", state.Attributes)
+	}
+	if state.Attributes["min_ram"] != "1024" || state.Attributes["max_ram"] != "65536" {
+		t.Fatalf("expected min_ram 1024 and max_ram 65536, got %q and %q", state.Attributes["min_ram"], state.Attributes["max_ram"])
+	}
+
+	state, err = testFakeRead(r, map[string]interface{}{
+		"tags":        map[string]interface{}{"role": "os"},
+		"most_recent": true,
+	}, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.ID != fakeImageBase64LTSID {
+		t.Fatalf("expected tags to select %s, got %s", fakeImageBase64LTSID, state.ID)
+	}
+
+	if _, err := testFakeRead(r, map[string]interface{}{
+		"name_regex": "^centos",
+	}, meta); err == nil {
+		t.Fatal("expected a name_regex matching no image to fail")
+	}
+}
@@ -0,0 +1,242 @@
+package triton
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/TritonDataCenter/triton-go/compute"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/pkg/errors"
+)
+
+const (
+	imageSortOrderAscending  = "asc"
+	imageSortOrderDescending = "desc"
+)
+
+// filterImageFunc is a function that is called to filter an Image from a
+// slice of Images based on a predicate.
+type filterImageFunc func(*compute.Image) bool
+
+// dataSourceImages returns schema for the Images data source.
+func dataSourceImages() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceImagesRead,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Description: "The name of the images. Supports the `*` and `?` wildcards.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"os": {
+				Description: "The underlying operating system of the images.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"version": {
+				Description: "The version of the images.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"public": {
+				Description: "Whether to return public as well as private images",
+				Type:        schema.TypeBool,
+				Optional:    true,
+			},
+			"state": {
+				Description: "The state of the images. By default, only `active` images are shown.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"owner": {
+				Description: "The UUID of the account which owns the images.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"type": {
+				Description: "The image type.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"tags": {
+				Description: "Tags, all of which the images must have with the same value.",
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"sort_order": {
+				Description:  "Order of the images by publication date, either `desc` (newest first) or `asc`.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      imageSortOrderDescending,
+				ValidateFunc: dataSourceImagesValidateSortOrder,
+			},
+			"limit": {
+				Description:  "The maximum number of images to return. By default all matching images are returned.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+
+			"images": {
+				Description: "The images matching the search criteria.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"version": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"os": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"published_at": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"tags": {
+							Type:     schema.TypeMap,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"owner": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// dataSourceImagesRead retrieves the images matching the filters supported
+// by CloudAPI, then narrows them down by wildcard name and tags before
+// sorting and limiting the results.
+func dataSourceImagesRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	c, err := client.Compute()
+	if err != nil {
+		return err
+	}
+
+	input := &compute.ListImagesInput{}
+	name, hasName := d.GetOk("name")
+	isWildcard := hasName && strings.ContainsAny(name.(string), "*?")
+	if hasName && !isWildcard {
+		input.Name = name.(string)
+	}
+	if os, hasOS := d.GetOk("os"); hasOS {
+		input.OS = os.(string)
+	}
+	if version, hasVersion := d.GetOk("version"); hasVersion {
+		input.Version = version.(string)
+	}
+	if public, hasPublic := d.GetOk("public"); hasPublic {
+		input.Public = public.(bool)
+	}
+	if state, hasState := d.GetOk("state"); hasState {
+		input.State = state.(string)
+	}
+	if owner, hasOwner := d.GetOk("owner"); hasOwner {
+		input.Owner = owner.(string)
+	}
+	if imageType, hasImageType := d.GetOk("type"); hasImageType {
+		input.Type = imageType.(string)
+	}
+
+	log.Printf("[DEBUG] triton_images: Reading images matching %+v", input)
+	images, err := c.Images().List(context.Background(), input)
+	if err != nil {
+		return errors.Wrap(err, "error retrieving images")
+	}
+
+	if isWildcard {
+		images = filterImages(images, func(image *compute.Image) bool {
+			return wildcardMatch(name.(string), image.Name)
+		})
+	}
+	if tags, hasTags := d.GetOk("tags"); hasTags {
+		images = filterImages(images, func(image *compute.Image) bool {
+			for k, v := range tags.(map[string]interface{}) {
+				if value, found := image.Tags[k]; !found || value != v.(string) {
+					return false
+				}
+			}
+			return true
+		})
+	}
+
+	if d.Get("sort_order").(string) == imageSortOrderAscending {
+		sort.Stable(imageSort(images))
+	} else {
+		sort.Stable(sort.Reverse(imageSort(images)))
+	}
+
+	if limit := d.Get("limit").(int); limit > 0 && len(images) > limit {
+		images = images[:limit]
+	}
+
+	log.Printf("[DEBUG] triton_images: Found %d matching images", len(images))
+
+	result := make([]map[string]interface{}, 0, len(images))
+	for _, image := range images {
+		result = append(result, map[string]interface{}{
+			"id":           image.ID,
+			"name":         image.Name,
+			"version":      image.Version,
+			"os":           image.OS,
+			"type":         image.Type,
+			"published_at": image.PublishedAt.Format(time.RFC3339),
+			"tags":         image.Tags,
+			"owner":        image.Owner,
+		})
+	}
+
+	d.SetId(time.Now().UTC().String())
+	if err := d.Set("images", result); err != nil {
+		return errors.Wrap(err, "error setting images")
+	}
+
+	return nil
+}
+
+func dataSourceImagesValidateSortOrder(value interface{}, name string) (warnings []string, errors []error) {
+	switch value.(string) {
+	case imageSortOrderAscending, imageSortOrderDescending:
+	default:
+		errors = append(errors, fmt.Errorf("%s must be one of %q or %q, got %q", name, imageSortOrderAscending, imageSortOrderDescending, value.(string)))
+	}
+
+	return warnings, errors
+}
+
+// filterImages iterates over a slice of Images, and returns a slice that
+// contains all of the Images the predicate returns a value of true for.
+func filterImages(images []*compute.Image, f filterImageFunc) (results []*compute.Image) {
+	for _, image := range images {
+		if f(image) {
+			results = append(results, image)
+		}
+	}
+	return
+}
@@ -0,0 +1,103 @@
+package triton
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccTritonImages_wildcard(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTritonImages_wildcard,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.triton_images.base", "images.#", "2"),
+					resource.TestMatchResourceAttr("data.triton_images.base", "images.0.name", regexp.MustCompile(`^base-64-`)),
+					resource.TestCheckResourceAttrSet("data.triton_images.base", "images.0.published_at"),
+				),
+			},
+		},
+	})
+}
+
+func TestFakeTritonImages_filters(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+	r := dataSourceImages()
+
+	cases := []struct {
+		name   string
+		config map[string]interface{}
+		ids    []string
+	}{
+		{
+			name:   "all newest first",
+			config: map[string]interface{}{},
+			ids:    []string{fakeImageUbuntuID, fakeImageSharedID, fakeImageBase64LTSID, fakeImageBase64LTSOldID},
+		},
+		{
+			name:   "exact name",
+			config: map[string]interface{}{"name": "base-64-lts"},
+			ids:    []string{fakeImageBase64LTSID, fakeImageBase64LTSOldID},
+		},
+		{
+			name:   "wildcard name oldest first",
+			config: map[string]interface{}{"name": "base-*", "sort_order": "asc"},
+			ids:    []string{fakeImageBase64LTSOldID, fakeImageBase64LTSID},
+		},
+		{
+			name:   "wildcard name with limit",
+			config: map[string]interface{}{"name": "*-24.04", "limit": 1},
+			ids:    []string{fakeImageUbuntuID},
+		},
+		{
+			name:   "tags",
+			config: map[string]interface{}{"tags": map[string]interface{}{"role": "os"}, "limit": 1},
+			ids:    []string{fakeImageBase64LTSID},
+		},
+		{
+			name:   "no results",
+			config: map[string]interface{}{"name": "centos-*"},
+			ids:    []string{},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			state, err := testFakeRead(r, tc.config, meta)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			data := r.Data(state)
+			images := data.Get("images").([]interface{})
+			if len(images) != len(tc.ids) {
+				t.Fatalf("expected %d images, got %d: %v", len(tc.ids), len(images), images)
+			}
+			for i, id := range tc.ids {
+				if got := images[i].(map[string]interface{})["id"]; got != id {
+					t.Errorf("expected image %d to be %q, got %q", i, id, got)
+				}
+			}
+		})
+	}
+
+	if _, errs := dataSourceImagesValidateSortOrder("newest", "sort_order"); len(errs) == 0 {
+		t.Fatal("expected sort_order \"newest\" to be rejected")
+	}
+	for _, limit := range []int{0, -1} {
+		if _, errs := dataSourceImages().Schema["limit"].ValidateFunc(limit, "limit"); len(errs) == 0 {
+			t.Fatalf("expected limit %d to be rejected", limit)
+		}
+	}
+}
+
+var testAccTritonImages_wildcard = `
+data "triton_images" "base" {
+	name = "base-64-*"
+	limit = 2
+}
+`
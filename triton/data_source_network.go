@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"log"
 
-	"github.com/TritonDataCenter/triton-go/network"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/pkg/errors"
 )
@@ -31,6 +30,59 @@ func dataSourceNetwork() *schema.Resource {
 				Type:        schema.TypeBool,
 				Computed:    true,
 			},
+			"description": {
+				Description: "The description of the Network.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"subnet": {
+				Description: "The CIDR formatted string that describes the Network.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"provision_start_ip": {
+				Description: "The first IP on the Network that may be assigned.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"provision_end_ip": {
+				Description: "The last IP on the Network that may be assigned.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"gateway": {
+				Description: "The gateway IP address of the Network.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"resolvers": {
+				Description: "The resolver IP addresses of the Network.",
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Computed:    true,
+			},
+			"routes": {
+				Description: "The static routes of the Network, from CIDR subnet to gateway IP address.",
+				Type:        schema.TypeMap,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Computed:    true,
+			},
+			"internet_nat": {
+				Description: "Whether a NAT zone provides Internet access to the Network.",
+				Type:        schema.TypeBool,
+				Computed:    true,
+			},
+			"pool": {
+				Description: "Whether this is a Network pool rather than a Network.",
+				Type:        schema.TypeBool,
+				Computed:    true,
+			},
+			"member_networks": {
+				Description: "The IDs of the Networks a Network pool allocates addresses from.",
+				Type:        schema.TypeList,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Computed:    true,
+			},
 		},
 	}
 }
@@ -48,14 +100,14 @@ func dataSourceNetworkRead(d *schema.ResourceData, meta interface{}) error {
 	}
 
 	log.Printf("[DEBUG] triton_network: Reading Network details.")
-	networks, err := net.List(context.Background(), &network.ListInput{})
+	networks, err := listNetworks(context.Background(), net)
 	if err != nil {
 		return errors.Wrap(err, "error retrieving Network details")
 	}
 
 	networkName := d.Get("name").(string)
 
-	var result *network.Network
+	var result *networkListEntry
 	for _, network := range networks {
 		if network.Name == networkName {
 			log.Printf("[DEBUG] triton_network: Found matching Network: %+v", network)
@@ -70,6 +122,16 @@ func dataSourceNetworkRead(d *schema.ResourceData, meta interface{}) error {
 	d.SetId(result.Id)
 	d.Set("public", result.Public)
 	d.Set("fabric", result.Fabric)
+	d.Set("description", result.Description)
+	d.Set("subnet", result.Subnet)
+	d.Set("provision_start_ip", result.ProvisioningStartIP)
+	d.Set("provision_end_ip", result.ProvisioningEndIP)
+	d.Set("gateway", result.Gateway)
+	d.Set("resolvers", result.Resolvers)
+	d.Set("routes", result.Routes)
+	d.Set("internet_nat", result.InternetNAT)
+	d.Set("pool", result.isPool())
+	d.Set("member_networks", result.Networks)
 
 	return nil
 }
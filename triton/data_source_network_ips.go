@@ -0,0 +1,96 @@
+package triton
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+// dataSourceNetworkIPs returns schema for the Network IPs data source.
+func dataSourceNetworkIPs() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceNetworkIPsRead,
+		Schema: map[string]*schema.Schema{
+			"network_id": {
+				Description: "The ID of the Network.",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+
+			"ips": {
+				Description: "The IP addresses of the Network which are in use or reserved.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"ip": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"reserved": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"managed": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"owner": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"belongs_to_uuid": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"belongs_to_type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// dataSourceNetworkIPsRead retrieves the IP addresses of a Network which are
+// allocated to machines, reserved or managed by Triton.
+func dataSourceNetworkIPsRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+
+	net, err := client.Network()
+	if err != nil {
+		return errors.Wrap(err, "error creating Network client")
+	}
+
+	networkID := d.Get("network_id").(string)
+
+	log.Printf("[DEBUG] triton_network_ips: Reading IPs of Network %q", networkID)
+	ips, err := listNetworkIPs(context.Background(), net, networkID)
+	if err != nil {
+		return errors.Wrap(err, "error retrieving Network IPs")
+	}
+
+	result := make([]map[string]interface{}, 0, len(ips))
+	for _, ip := range ips {
+		result = append(result, map[string]interface{}{
+			"ip":              ip.IP,
+			"reserved":        ip.Reserved,
+			"managed":         ip.Managed,
+			"owner":           ip.OwnerUUID,
+			"belongs_to_uuid": ip.BelongsToUUID,
+			"belongs_to_type": ip.BelongsToType,
+		})
+	}
+
+	d.SetId(time.Now().UTC().String())
+	if err := d.Set("ips", result); err != nil {
+		return errors.Wrap(err, "error setting Network IPs")
+	}
+
+	return nil
+}
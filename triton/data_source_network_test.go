@@ -51,6 +51,59 @@ func TestAccTritonNetwork_NotFound(t *testing.T) {
 	})
 }
 
+func TestFakeTritonNetwork_attributes(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+
+	state, err := testFakeRead(dataSourceNetwork(), map[string]interface{}{
+		"name": "My-Fabric-Network",
+	}, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if state.ID != fakeFabricNetworkID {
+		t.Fatalf("expected network %q, got %q", fakeFabricNetworkID, state.ID)
+	}
+	for k, v := range map[string]string{
+		"fabric":             "true",
+		"public":             "false",
+		"subnet":             "192.168.128.0/22",
+		"provision_start_ip": "192.168.128.5",
+		"provision_end_ip":   "192.168.131.250",
+		"gateway":            "192.168.128.1",
+		"resolvers.#":        "2",
+		"internet_nat":       "true",
+		"description":        "Default fabric network",
+	} {
+		if state.Attributes[k] != v {
+			t.Errorf("expected %s to be %q, got %q", k, v, state.Attributes[k])
+		}
+	}
+}
+
+func TestFakeTritonNetwork_pool(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+	r := dataSourceNetwork()
+
+	state, err := testFakeRead(r, map[string]interface{}{
+		"name": "Public-Pool",
+	}, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := r.Data(state)
+	if !data.Get("pool").(bool) {
+		t.Fatal("expected the network to be a pool")
+	}
+	members := data.Get("member_networks").([]interface{})
+	if len(members) != 1 || members[0] != fakePublicNetworkID {
+		t.Fatalf("expected the pool to hold %q, got %v", fakePublicNetworkID, members)
+	}
+}
+
 func testAccCheckTritonNetworkDataSourceID(name, networkName string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		conn := testAccProvider.Meta().(*Client)
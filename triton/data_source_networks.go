@@ -0,0 +1,187 @@
+package triton
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+// filterNetworkFunc is a function that is called to filter a Network from a
+// slice of Networks based on a predicate.
+type filterNetworkFunc func(*networkListEntry) bool
+
+// dataSourceNetworks returns schema for the Networks data source.
+func dataSourceNetworks() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceNetworksRead,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Description: "The name of the Networks. Supports the `*` and `?` wildcards.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"public": {
+				Description: "Whether to only return public, or only private, Networks.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+			},
+			"fabric": {
+				Description: "Whether to only return Fabric, or only non-Fabric, Networks.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+			},
+
+			"networks": {
+				Description: "The Networks matching the search criteria.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"public": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"fabric": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"description": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"subnet": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"provision_start_ip": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"provision_end_ip": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"gateway": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"resolvers": {
+							Type:     schema.TypeList,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+							Computed: true,
+						},
+						"routes": {
+							Type:     schema.TypeMap,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+							Computed: true,
+						},
+						"internet_nat": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"pool": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"member_networks": {
+							Type:     schema.TypeList,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// dataSourceNetworksRead retrieves all the networks and network pools which
+// can be used by the given account from the Networks API, then narrows them
+// down by name and by whether they are public or on a fabric.
+func dataSourceNetworksRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+
+	net, err := client.Network()
+	if err != nil {
+		return errors.Wrap(err, "error creating Network client")
+	}
+
+	log.Printf("[DEBUG] triton_networks: Reading Network details.")
+	networks, err := listNetworks(context.Background(), net)
+	if err != nil {
+		return errors.Wrap(err, "error retrieving Network details")
+	}
+
+	if name, hasName := d.GetOk("name"); hasName {
+		networks = filterNetworks(networks, func(n *networkListEntry) bool {
+			if strings.ContainsAny(name.(string), "*?") {
+				return wildcardMatch(name.(string), n.Name)
+			}
+			return n.Name == name.(string)
+		})
+	}
+	// Both flags are meaningful when false, so look at whether they are set
+	// rather than at their value.
+	if public, hasPublic := d.GetOkExists("public"); hasPublic {
+		networks = filterNetworks(networks, func(n *networkListEntry) bool {
+			return n.Public == public.(bool)
+		})
+	}
+	if fabric, hasFabric := d.GetOkExists("fabric"); hasFabric {
+		networks = filterNetworks(networks, func(n *networkListEntry) bool {
+			return n.Fabric == fabric.(bool)
+		})
+	}
+
+	log.Printf("[DEBUG] triton_networks: Found %d matching Networks", len(networks))
+
+	result := make([]map[string]interface{}, 0, len(networks))
+	for _, n := range networks {
+		result = append(result, map[string]interface{}{
+			"id":                 n.Id,
+			"name":               n.Name,
+			"public":             n.Public,
+			"fabric":             n.Fabric,
+			"description":        n.Description,
+			"subnet":             n.Subnet,
+			"provision_start_ip": n.ProvisioningStartIP,
+			"provision_end_ip":   n.ProvisioningEndIP,
+			"gateway":            n.Gateway,
+			"resolvers":          n.Resolvers,
+			"routes":             n.Routes,
+			"internet_nat":       n.InternetNAT,
+			"pool":               n.isPool(),
+			"member_networks":    n.Networks,
+		})
+	}
+
+	d.SetId(time.Now().UTC().String())
+	if err := d.Set("networks", result); err != nil {
+		return errors.Wrap(err, "error setting Networks")
+	}
+
+	return nil
+}
+
+// filterNetworks iterates over a slice of Networks, and returns a slice that
+// contains all of the Networks the predicate returns a value of true for.
+func filterNetworks(networks []*networkListEntry, f filterNetworkFunc) (results []*networkListEntry) {
+	for _, n := range networks {
+		if f(n) {
+			results = append(results, n)
+		}
+	}
+	return
+}
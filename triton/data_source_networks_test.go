@@ -0,0 +1,98 @@
+package triton
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccTritonNetworks_public(t *testing.T) {
+	publicNetwork := testAccConfig(t, "public_network_name")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTritonNetworks_public,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckTypeSetElemNestedAttrs("data.triton_networks.public", "networks.*", map[string]string{
+						"name":   publicNetwork,
+						"public": "true",
+					}),
+				),
+			},
+		},
+	})
+}
+
+func TestFakeTritonNetworks_filters(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+	r := dataSourceNetworks()
+
+	cases := []struct {
+		name   string
+		config map[string]interface{}
+		ids    []string
+	}{
+		{
+			name:   "all",
+			config: map[string]interface{}{},
+			ids:    []string{fakePublicNetworkID, fakePrivateNetworkID, fakeFabricNetworkID, fakePublicPoolID},
+		},
+		{
+			name:   "public",
+			config: map[string]interface{}{"public": true},
+			ids:    []string{fakePublicNetworkID, fakePublicPoolID},
+		},
+		{
+			name:   "private",
+			config: map[string]interface{}{"public": false},
+			ids:    []string{fakePrivateNetworkID, fakeFabricNetworkID},
+		},
+		{
+			name:   "not fabric",
+			config: map[string]interface{}{"fabric": false},
+			ids:    []string{fakePublicNetworkID, fakePrivateNetworkID, fakePublicPoolID},
+		},
+		{
+			name:   "wildcard name",
+			config: map[string]interface{}{"name": "MNX-Triton-*"},
+			ids:    []string{fakePublicNetworkID, fakePrivateNetworkID},
+		},
+		{
+			name:   "exact name",
+			config: map[string]interface{}{"name": "My-Fabric-Network"},
+			ids:    []string{fakeFabricNetworkID},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			state, err := testFakeRead(r, tc.config, meta)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			networks := r.Data(state).Get("networks").([]interface{})
+			if len(networks) != len(tc.ids) {
+				t.Fatalf("expected %d networks, got %d: %v", len(tc.ids), len(networks), networks)
+			}
+			found := map[string]bool{}
+			for _, n := range networks {
+				found[n.(map[string]interface{})["id"].(string)] = true
+			}
+			for _, id := range tc.ids {
+				if !found[id] {
+					t.Errorf("expected network %q in %v", id, networks)
+				}
+			}
+		})
+	}
+}
+
+var testAccTritonNetworks_public = `
+data "triton_networks" "public" {
+	public = true
+}
+`
@@ -0,0 +1,243 @@
+package triton
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/TritonDataCenter/triton-go/compute"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+// packageRangeAttributes are the numeric package properties which can be
+// constrained by the `min_` and `max_` arguments of the Packages data source.
+var packageRangeAttributes = []string{"memory", "disk", "swap", "vcpus", "lwps"}
+
+// filterPackageFunc is a function that is called to filter a Package from a
+// slice of Packages based on a predicate.
+type filterPackageFunc func(*compute.Package) bool
+
+// dataSourcePackages returns schema for the Packages data source.
+func dataSourcePackages() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"name_regex": {
+			Description:  "A regular expression the name of the packages must match.",
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validateRegexp,
+		},
+		"group": {
+			Description: "The group of the packages.",
+			Type:        schema.TypeString,
+			Optional:    true,
+		},
+		"smallest": {
+			Description: "Only return the smallest matching package, failing if there is none.",
+			Type:        schema.TypeBool,
+			Optional:    true,
+		},
+
+		"packages": {
+			Description: "The packages matching the search criteria, smallest first.",
+			Type:        schema.TypeList,
+			Computed:    true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"id": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+					"name": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+					"memory": {
+						Type:     schema.TypeInt,
+						Computed: true,
+					},
+					"disk": {
+						Type:     schema.TypeInt,
+						Computed: true,
+					},
+					"swap": {
+						Type:     schema.TypeInt,
+						Computed: true,
+					},
+					"vcpus": {
+						Type:     schema.TypeInt,
+						Computed: true,
+					},
+					"lwps": {
+						Type:     schema.TypeInt,
+						Computed: true,
+					},
+					"version": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+					"group": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+					"description": {
+						Type:     schema.TypeString,
+						Computed: true,
+					},
+				},
+			},
+		},
+	}
+
+	for _, attr := range packageRangeAttributes {
+		s["min_"+attr] = &schema.Schema{
+			Description:  fmt.Sprintf("The minimum %s of the packages.", attr),
+			Type:         schema.TypeInt,
+			Optional:     true,
+			ValidateFunc: dataSourcePackagesValidateBound,
+		}
+		s["max_"+attr] = &schema.Schema{
+			Description:  fmt.Sprintf("The maximum %s of the packages.", attr),
+			Type:         schema.TypeInt,
+			Optional:     true,
+			ValidateFunc: dataSourcePackagesValidateBound,
+		}
+	}
+
+	return &schema.Resource{
+		Read:   dataSourcePackagesRead,
+		Schema: s,
+	}
+}
+
+// dataSourcePackagesRead retrieves the packages of a group, then narrows them
+// down by name and the given ranges before sorting them from the smallest to
+// the largest.
+func dataSourcePackagesRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	c, err := client.Compute()
+	if err != nil {
+		return err
+	}
+
+	input := &compute.ListPackagesInput{}
+	if group, hasGroup := d.GetOk("group"); hasGroup {
+		input.Group = group.(string)
+	}
+
+	log.Printf("[DEBUG] triton_packages: Reading packages matching %+v", input)
+	packages, err := c.Packages().List(context.Background(), input)
+	if err != nil {
+		return errors.Wrap(err, "error retrieving packages")
+	}
+
+	if nameRegex, hasNameRegex := d.GetOk("name_regex"); hasNameRegex {
+		r := regexp.MustCompile(nameRegex.(string))
+		packages = filterPackages(packages, func(pkg *compute.Package) bool {
+			return r.MatchString(pkg.Name)
+		})
+	}
+	for _, attr := range packageRangeAttributes {
+		attr := attr
+		// A bound of 0 is a bound too, for swap or vCPUs for example.
+		min, hasMin := d.GetOkExists("min_" + attr)
+		max, hasMax := d.GetOkExists("max_" + attr)
+		if hasMin && hasMax && min.(int) > max.(int) {
+			return fmt.Errorf("min_%s (%d) must not be greater than max_%s (%d)", attr, min.(int), attr, max.(int))
+		}
+		if hasMin || hasMax {
+			packages = filterPackages(packages, func(pkg *compute.Package) bool {
+				value := packageRangeValue(pkg, attr)
+				return (!hasMin || value >= int64(min.(int))) && (!hasMax || value <= int64(max.(int)))
+			})
+		}
+	}
+
+	sort.SliceStable(packages, func(i, j int) bool {
+		return packageLess(packages[i], packages[j])
+	})
+
+	if d.Get("smallest").(bool) {
+		if len(packages) == 0 {
+			return fmt.Errorf("your query returned no results, please change " +
+				"your filter criteria and try again")
+		}
+		packages = packages[:1]
+	}
+
+	log.Printf("[DEBUG] triton_packages: Found %d matching packages", len(packages))
+
+	result := make([]map[string]interface{}, 0, len(packages))
+	for _, pkg := range packages {
+		result = append(result, map[string]interface{}{
+			"id":          pkg.ID,
+			"name":        pkg.Name,
+			"memory":      pkg.Memory,
+			"disk":        pkg.Disk,
+			"swap":        pkg.Swap,
+			"vcpus":       pkg.VCPUs,
+			"lwps":        pkg.LWPs,
+			"version":     pkg.Version,
+			"group":       pkg.Group,
+			"description": pkg.Description,
+		})
+	}
+
+	d.SetId(time.Now().UTC().String())
+	if err := d.Set("packages", result); err != nil {
+		return errors.Wrap(err, "error setting packages")
+	}
+
+	return nil
+}
+
+func dataSourcePackagesValidateBound(value interface{}, name string) (warnings []string, errors []error) {
+	if value.(int) < 0 {
+		errors = append(errors, fmt.Errorf("%s must not be negative, got %d", name, value.(int)))
+	}
+
+	return warnings, errors
+}
+
+// packageRangeValue returns the value of one of packageRangeAttributes.
+func packageRangeValue(pkg *compute.Package, attr string) int64 {
+	switch attr {
+	case "memory":
+		return pkg.Memory
+	case "disk":
+		return pkg.Disk
+	case "swap":
+		return pkg.Swap
+	case "vcpus":
+		return pkg.VCPUs
+	case "lwps":
+		return pkg.LWPs
+	}
+	return 0
+}
+
+// packageLess orders packages by size. CloudAPI does not expose prices, and
+// packages are priced by the resources they provide, so memory comes first,
+// then vCPUs, disk and swap, with the name as a tie-breaker.
+func packageLess(a, b *compute.Package) bool {
+	for _, attr := range []string{"memory", "vcpus", "disk", "swap"} {
+		if va, vb := packageRangeValue(a, attr), packageRangeValue(b, attr); va != vb {
+			return va < vb
+		}
+	}
+	return a.Name < b.Name
+}
+
+// filterPackages iterates over a slice of Packages, and returns a slice that
+// contains all of the Packages the predicate returns a value of true for.
+func filterPackages(packages []*compute.Package, f filterPackageFunc) (results []*compute.Package) {
+	for _, pkg := range packages {
+		if f(pkg) {
+			results = append(results, pkg)
+		}
+	}
+	return
+}
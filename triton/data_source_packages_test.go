@@ -0,0 +1,109 @@
+package triton
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccTritonPackages_smallest(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTritonPackages_smallest,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.triton_packages.small", "packages.#", "1"),
+					resource.TestCheckResourceAttrSet("data.triton_packages.small", "packages.0.name"),
+				),
+			},
+		},
+	})
+}
+
+func TestFakeTritonPackages_filters(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+	r := dataSourcePackages()
+
+	cases := []struct {
+		name   string
+		config map[string]interface{}
+		names  []string
+	}{
+		{
+			name:   "all smallest first",
+			config: map[string]interface{}{},
+			names:  []string{"g1.nano", "g1.micro", "g1.small", "g1.medium", "g1.large", "m1.large"},
+		},
+		{
+			name:   "memory and vcpus ranges",
+			config: map[string]interface{}{"min_memory": 4096, "min_vcpus": 2},
+			names:  []string{"g1.medium", "g1.large", "m1.large"},
+		},
+		{
+			name:   "smallest match",
+			config: map[string]interface{}{"min_memory": 4096, "min_vcpus": 2, "smallest": true},
+			names:  []string{"g1.medium"},
+		},
+		{
+			name:   "maximum disk",
+			config: map[string]interface{}{"max_disk": 40960},
+			names:  []string{"g1.nano", "g1.micro", "g1.small"},
+		},
+		{
+			name:   "group and name regex",
+			config: map[string]interface{}{"group": "General Purpose", "name_regex": `\.(nano|large)$`},
+			names:  []string{"g1.nano", "g1.large"},
+		},
+		{
+			name:   "zero upper bound",
+			config: map[string]interface{}{"max_swap": 0},
+			names:  []string{},
+		},
+		{
+			name:   "zero lower bound",
+			config: map[string]interface{}{"min_vcpus": 0, "max_vcpus": 1},
+			names:  []string{"g1.nano", "g1.micro", "g1.small"},
+		},
+		{
+			name:   "no results",
+			config: map[string]interface{}{"min_lwps": 5000},
+			names:  []string{},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			state, err := testFakeRead(r, tc.config, meta)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			packages := r.Data(state).Get("packages").([]interface{})
+			if len(packages) != len(tc.names) {
+				t.Fatalf("expected %d packages, got %d: %v", len(tc.names), len(packages), packages)
+			}
+			for i, name := range tc.names {
+				if got := packages[i].(map[string]interface{})["name"]; got != name {
+					t.Errorf("expected package %d to be %q, got %q", i, name, got)
+				}
+			}
+		})
+	}
+
+	if _, err := testFakeRead(r, map[string]interface{}{"min_lwps": 5000, "smallest": true}, meta); err == nil {
+		t.Fatal("expected an error when no package matches and smallest is set")
+	}
+	if _, err := testFakeRead(r, map[string]interface{}{"min_memory": 2048, "max_memory": 1024}, meta); err == nil {
+		t.Fatal("expected an error for an empty memory range")
+	}
+}
+
+var testAccTritonPackages_smallest = `
+data "triton_packages" "small" {
+	min_memory = 4096
+	min_vcpus = 2
+	smallest = true
+}
+`
@@ -0,0 +1,60 @@
+package triton
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+// dataSourceVolumeSizes returns schema for the Volume Sizes data source.
+func dataSourceVolumeSizes() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVolumeSizesRead,
+		Schema: map[string]*schema.Schema{
+			"type": {
+				Description: "The type of volume to list the sizes of.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "tritonnfs",
+			},
+
+			"sizes": {
+				Description: "The sizes, in MiB, volumes of the type can be created with, smallest first.",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeInt},
+			},
+		},
+	}
+}
+
+// dataSourceVolumeSizesRead retrieves the sizes volumes of a type can be
+// created with.
+func dataSourceVolumeSizesRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+
+	c, err := client.Compute()
+	if err != nil {
+		return errors.Wrap(err, "error creating Compute client")
+	}
+
+	volumeType := d.Get("type").(string)
+
+	log.Printf("[DEBUG] triton_volume_sizes: Reading sizes of %q volumes.", volumeType)
+	sizes, err := listVolumeSizes(context.Background(), c, volumeType)
+	if err != nil {
+		return errors.Wrap(err, "error retrieving Volume sizes")
+	}
+
+	log.Printf("[DEBUG] triton_volume_sizes: Found %d sizes", len(sizes))
+
+	d.SetId(time.Now().UTC().String())
+	if err := d.Set("sizes", sizes); err != nil {
+		return errors.Wrap(err, "error setting Volume sizes")
+	}
+
+	return nil
+}
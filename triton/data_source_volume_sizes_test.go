@@ -0,0 +1,53 @@
+package triton
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccTritonVolumeSizes_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTritonVolumeSizes_basic,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.triton_volume_sizes.test", "sizes.0"),
+				),
+			},
+		},
+	})
+}
+
+var testAccTritonVolumeSizes_basic = `
+data "triton_volume_sizes" "test" {
+	type = "tritonnfs"
+}
+`
+
+func TestFakeTritonVolumeSizes_basic(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+
+	r := dataSourceVolumeSizes()
+	state, err := testFakeRead(r, map[string]interface{}{}, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sizes := r.Data(state).Get("sizes").([]interface{})
+	if len(sizes) != len(fakeVolumeSizes) {
+		t.Fatalf("expected %d sizes, got %v", len(fakeVolumeSizes), sizes)
+	}
+	for i, size := range fakeVolumeSizes {
+		if int64(sizes[i].(int)) != size {
+			t.Fatalf("expected the sizes smallest first, got %v", sizes)
+		}
+	}
+
+	if _, err := testFakeRead(r, map[string]interface{}{"type": "unknown"}, meta); err == nil {
+		t.Fatal("expected an unknown volume type to fail")
+	}
+}
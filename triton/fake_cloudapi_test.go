@@ -0,0 +1,2447 @@
+package triton
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TritonDataCenter/triton-go/account"
+	"github.com/TritonDataCenter/triton-go/compute"
+	"github.com/TritonDataCenter/triton-go/network"
+	ctyjson "github.com/hashicorp/go-cty/cty/json"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	fakeAccount   = "fake-account"
+	fakeAccountID = "b1c2d3e4-f5a6-4b7c-8d9e-0f1a2b3c4d5e"
+
+	fakePublicNetworkID  = "0b4e8d4c-3f3e-4d59-9c2a-d7b0f0a5a7d1"
+	fakePrivateNetworkID = "5c3b0a8e-6d1f-4c4a-8a47-2a1b3c4d5e6f"
+	fakeFabricNetworkID  = "9f6a2e7b-1c0d-4b3e-8f5a-6e7d8c9b0a1f"
+	fakeFabricVLANID     = 2
+	fakePublicPoolID     = "e2f4a6c8-0b1d-4e3f-9a5c-7b9d1f3a5c7e"
+
+	fakeImageBase64LTSID    = "6e8b2b4a-8f0c-4c6a-bb1e-2a7d0c9e1f30"
+	fakeImageBase64LTSOldID = "1a2b3c4d-5e6f-4a7b-8c9d-0e1f2a3b4c5d"
+	fakeImageUbuntuID       = "c7d8e9f0-a1b2-4c3d-9e4f-5a6b7c8d9e0f"
+	fakeImageSharedID       = "e4f5a6b7-c8d9-4e0f-a1b2-c3d4e5f6a7b8"
+	fakeImageOwner          = "930896af-bf8c-48d4-885c-6573a94b1853"
+	fakePartnerAccountID    = "7b3e2f1a-0c9d-4e8b-a6f5-4d3c2b1a0e9f"
+)
+
+// fakeCloudAPIKey is the RSA key used to sign requests to every fake CloudAPI
+// instance. Generating it once keeps the offline tests fast.
+var fakeCloudAPIKey struct {
+	once sync.Once
+	key  *rsa.PrivateKey
+	err  error
+}
+
+// fakeCloudAPI is an in-process stand-in for CloudAPI. It keeps all of its
+// state in memory, completes asynchronous jobs (provisioning, NIC changes,
+// snapshots and so on) immediately, and verifies the http-signature produced
+// by Config.newClient on every request. This allows resources and data
+// sources to be exercised without a Triton endpoint or any credentials.
+type fakeCloudAPI struct {
+	Account string
+	KeyID   string
+	KeyFile string
+
+	server    *httptest.Server
+	publicKey *rsa.PublicKey
+	routes    []fakeRoute
+
+	mu       sync.Mutex
+	serial   int
+	machines map[string]*fakeMachine
+	volumes  map[string]*compute.Volume
+	vlans    map[int]*network.FabricVLAN
+	networks map[string]*fakeNetwork
+	rules    map[string]*firewallRule
+	keys     map[string]*account.Key
+	images   map[string]*compute.Image
+	packages map[string]*compute.Package
+
+	// requests logs the method and path, relative to the account, of every
+	// request, for tests which check the order of calls.
+	requests []string
+}
+
+// fakeRoute maps a method and a path pattern, relative to the account, to a
+// handler. A "*" segment in the pattern matches any single path segment and
+// is passed to the handler as an argument.
+type fakeRoute struct {
+	method  string
+	pattern []string
+	handler func(w http.ResponseWriter, r *http.Request, args []string)
+}
+
+type fakeMachine struct {
+	ID                 string                 `json:"id"`
+	Name               string                 `json:"name"`
+	Type               string                 `json:"type"`
+	Brand              string                 `json:"brand"`
+	State              string                 `json:"state"`
+	Image              string                 `json:"image"`
+	Memory             int                    `json:"memory"`
+	Disk               int                    `json:"disk"`
+	Metadata           map[string]string      `json:"metadata"`
+	Tags               map[string]interface{} `json:"tags"`
+	Created            time.Time              `json:"created"`
+	Updated            time.Time              `json:"updated"`
+	Docker             bool                   `json:"docker"`
+	IPs                []string               `json:"ips"`
+	Networks           []string               `json:"networks"`
+	PrimaryIP          string                 `json:"primaryIp"`
+	FirewallEnabled    bool                   `json:"firewall_enabled"`
+	ComputeNode        string                 `json:"compute_node"`
+	Package            string                 `json:"package"`
+	DomainNames        []string               `json:"dns_names"`
+	DeletionProtection bool                   `json:"deletion_protection"`
+	DelegateDataset    bool                   `json:"delegate_dataset,omitempty"`
+
+	nics        []*compute.NIC
+	snapshots   []*fakeSnapshot
+	reboots     int
+	rebootPolls int
+	rollbacks   []string
+}
+
+type fakeSnapshot struct {
+	Name    string    `json:"name"`
+	State   string    `json:"state"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+type fakeNetwork struct {
+	network.Network
+	Networks []string `json:"networks,omitempty"`
+
+	vlanID   int
+	used     map[string]string
+	reserved map[string]bool
+}
+
+// newFakeCloudAPI starts a fake CloudAPI seeded with a small but realistic
+// account: a public and a private network, a pool holding the public network,
+// a default fabric VLAN with one network, a handful of packages and images,
+// and the SSH key used to sign requests. The server is shut down when the test completes.
+func newFakeCloudAPI(t *testing.T) *fakeCloudAPI {
+	t.Helper()
+
+	fakeCloudAPIKey.once.Do(func() {
+		fakeCloudAPIKey.key, fakeCloudAPIKey.err = rsa.GenerateKey(rand.Reader, 2048)
+	})
+	if fakeCloudAPIKey.err != nil {
+		t.Fatalf("error generating fake CloudAPI key: %s", fakeCloudAPIKey.err)
+	}
+	key := fakeCloudAPIKey.key
+
+	sshPublicKey, err := ssh.NewPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("error creating SSH public key: %s", err)
+	}
+
+	keyFile := filepath.Join(t.TempDir(), "id_rsa")
+	keyPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	})
+	if err := os.WriteFile(keyFile, keyPEM, 0600); err != nil {
+		t.Fatalf("error writing fake CloudAPI key: %s", err)
+	}
+
+	f := &fakeCloudAPI{
+		Account:   fakeAccount,
+		KeyID:     strings.TrimPrefix(ssh.FingerprintLegacyMD5(sshPublicKey), "MD5:"),
+		KeyFile:   keyFile,
+		publicKey: &key.PublicKey,
+		machines:  map[string]*fakeMachine{},
+		volumes:   map[string]*compute.Volume{},
+		vlans:     map[int]*network.FabricVLAN{},
+		networks:  map[string]*fakeNetwork{},
+		rules:     map[string]*firewallRule{},
+		keys:      map[string]*account.Key{},
+		images:    map[string]*compute.Image{},
+		packages:  map[string]*compute.Package{},
+	}
+	f.registerRoutes()
+	f.seed(strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPublicKey))))
+
+	f.server = httptest.NewServer(f)
+	t.Cleanup(f.server.Close)
+
+	return f
+}
+
+// URL returns the endpoint of the fake CloudAPI.
+func (f *fakeCloudAPI) URL() string {
+	return f.server.URL
+}
+
+// Client returns a provider client for the fake CloudAPI. It is built through
+// Config.newClient, so every request carries a real http-signature.
+func (f *fakeCloudAPI) Client(t *testing.T) *Client {
+	t.Helper()
+
+	config := Config{
+		Account:     f.Account,
+		URL:         f.URL(),
+		KeyID:       f.KeyID,
+		KeyMaterial: f.KeyFile,
+	}
+	if err := config.validate(); err != nil {
+		t.Fatalf("error validating fake CloudAPI configuration: %s", err)
+	}
+
+	client, err := config.newClient()
+	if err != nil {
+		t.Fatalf("error creating fake CloudAPI client: %s", err)
+	}
+
+	return client
+}
+
+// ProviderConfig returns a provider block which points the Triton provider
+// at the fake CloudAPI.
+func (f *fakeCloudAPI) ProviderConfig() string {
+	return fmt.Sprintf(`
+provider "triton" {
+  account      = %q
+  key_id       = %q
+  key_material = %q
+  url          = %q
+}
+`, f.Account, f.KeyID, f.KeyFile, f.URL())
+}
+
+// testFakePreCheck skips Terraform CLI driven tests against the fake CloudAPI
+// when the testing framework has no Terraform binary to work with.
+func testFakePreCheck(t *testing.T) {
+	if os.Getenv("TF_ACC_TERRAFORM_PATH") != "" {
+		return
+	}
+	if _, err := exec.LookPath("terraform"); err != nil {
+		t.Skip("terraform not found in PATH, set TF_ACC_TERRAFORM_PATH to run tests against the fake CloudAPI")
+	}
+}
+
+// testFakeApply plans the given configuration against the current state of a
+// resource and applies the result, much like a single `terraform apply`.
+func testFakeApply(r *schema.Resource, state *terraform.InstanceState, config map[string]interface{}, meta interface{}) (*terraform.InstanceState, error) {
+	diff, err := testFakePlan(r, state, config, meta)
+	if err != nil {
+		return state, err
+	}
+	if diff == nil || diff.Empty() {
+		return state, nil
+	}
+
+	newState, diags := r.Apply(context.Background(), state, diff, meta)
+	if diags.HasError() {
+		return newState, fmt.Errorf("%v", diags)
+	}
+
+	return newState, nil
+}
+
+// testFakePlan validates the given configuration and returns the changes
+// Terraform would make to bring the state of a resource in line with it.
+func testFakePlan(r *schema.Resource, state *terraform.InstanceState, config map[string]interface{}, meta interface{}) (*terraform.InstanceDiff, error) {
+	c, state, err := testFakeConfig(r, state, config)
+	if err != nil {
+		return nil, err
+	}
+	if diags := r.Validate(c); diags.HasError() {
+		return nil, fmt.Errorf("%v", diags)
+	}
+
+	return r.Diff(context.Background(), state, c, meta)
+}
+
+// testFakeConfig converts a configuration into the form Terraform hands it to
+// the provider, and attaches it and the prior state to a copy of the state so
+// that they are available through GetRawConfig and GetRawState.
+func testFakeConfig(r *schema.Resource, state *terraform.InstanceState, config map[string]interface{}) (*terraform.ResourceConfig, *terraform.InstanceState, error) {
+	b, err := json.Marshal(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	block := r.CoreConfigSchema()
+	val, err := ctyjson.Unmarshal(b, block.ImpliedType())
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid configuration: %s", err)
+	}
+	if val, err = block.CoerceValue(val); err != nil {
+		return nil, nil, fmt.Errorf("invalid configuration: %s", err)
+	}
+
+	if state == nil {
+		state = &terraform.InstanceState{}
+	} else {
+		state = state.DeepCopy()
+		if state.RawState, err = state.AttrsAsObjectValue(block.ImpliedType()); err != nil {
+			return nil, nil, fmt.Errorf("invalid state: %s", err)
+		}
+	}
+	state.RawConfig = val
+
+	return terraform.NewResourceConfigShimmed(val, block), state, nil
+}
+
+// testFakeRefresh refreshes the state of a resource. A nil state is returned
+// once the resource no longer exists.
+func testFakeRefresh(r *schema.Resource, state *terraform.InstanceState, meta interface{}) (*terraform.InstanceState, error) {
+	newState, diags := r.RefreshWithoutUpgrade(context.Background(), state, meta)
+	if diags.HasError() {
+		return newState, fmt.Errorf("%v", diags)
+	}
+
+	return newState, nil
+}
+
+// testFakeDestroy destroys the resource described by the given state.
+func testFakeDestroy(r *schema.Resource, state *terraform.InstanceState, meta interface{}) error {
+	_, diags := r.Apply(context.Background(), state, &terraform.InstanceDiff{Destroy: true}, meta)
+	if diags.HasError() {
+		return fmt.Errorf("%v", diags)
+	}
+
+	return nil
+}
+
+// testFakeRead reads a data source with the given configuration.
+func testFakeRead(r *schema.Resource, config map[string]interface{}, meta interface{}) (*terraform.InstanceState, error) {
+	c, state, err := testFakeConfig(r, nil, config)
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := r.Diff(context.Background(), state, c, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	state, diags := r.ReadDataApply(context.Background(), diff, meta)
+	if diags.HasError() {
+		return state, fmt.Errorf("%v", diags)
+	}
+
+	return state, nil
+}
+
+func (f *fakeCloudAPI) seed(authorizedKey string) {
+	f.keys["fake-key"] = &account.Key{
+		Name:        "fake-key",
+		Fingerprint: f.KeyID,
+		Key:         authorizedKey,
+	}
+
+	f.networks[fakePublicNetworkID] = &fakeNetwork{
+		Network: network.Network{
+			Id:                  fakePublicNetworkID,
+			Name:                "MNX-Triton-Public",
+			Public:              true,
+			Description:         "Public network",
+			Subnet:              "203.0.113.0/24",
+			ProvisioningStartIP: "203.0.113.10",
+			ProvisioningEndIP:   "203.0.113.250",
+			Gateway:             "203.0.113.1",
+			Resolvers:           []string{"8.8.8.8", "8.8.4.4"},
+			Routes:              map[string]string{},
+		},
+		vlanID: -1,
+		used:   map[string]string{},
+	}
+	f.networks[fakePrivateNetworkID] = &fakeNetwork{
+		Network: network.Network{
+			Id:                  fakePrivateNetworkID,
+			Name:                "MNX-Triton-Private",
+			Description:         "Private network",
+			Subnet:              "10.64.0.0/21",
+			ProvisioningStartIP: "10.64.0.10",
+			ProvisioningEndIP:   "10.64.7.250",
+			Gateway:             "10.64.0.1",
+			Resolvers:           []string{"8.8.8.8", "8.8.4.4"},
+			Routes:              map[string]string{},
+		},
+		vlanID: -1,
+		used:   map[string]string{},
+	}
+
+	f.networks[fakePublicPoolID] = &fakeNetwork{
+		Network: network.Network{
+			Id:     fakePublicPoolID,
+			Name:   "Public-Pool",
+			Public: true,
+		},
+		Networks: []string{fakePublicNetworkID},
+		vlanID:   -1,
+		used:     map[string]string{},
+	}
+
+	f.vlans[fakeFabricVLANID] = &network.FabricVLAN{
+		ID:          fakeFabricVLANID,
+		Name:        "My-Fabric-VLAN",
+		Description: "Default fabric VLAN",
+	}
+	f.networks[fakeFabricNetworkID] = &fakeNetwork{
+		Network: network.Network{
+			Id:                  fakeFabricNetworkID,
+			Name:                "My-Fabric-Network",
+			Fabric:              true,
+			Description:         "Default fabric network",
+			Subnet:              "192.168.128.0/22",
+			ProvisioningStartIP: "192.168.128.5",
+			ProvisioningEndIP:   "192.168.131.250",
+			Gateway:             "192.168.128.1",
+			Resolvers:           []string{"8.8.8.8", "8.8.4.4"},
+			Routes:              map[string]string{},
+			InternetNAT:         true,
+		},
+		vlanID: fakeFabricVLANID,
+		used:   map[string]string{},
+	}
+
+	for i, spec := range []struct {
+		name   string
+		memory int64
+		vcpus  int64
+		group  string
+	}{
+		{"g1.nano", 512, 1, "General Purpose"},
+		{"g1.micro", 1024, 1, "General Purpose"},
+		{"g1.small", 2048, 1, "General Purpose"},
+		{"g1.medium", 4096, 2, "General Purpose"},
+		{"g1.large", 8192, 4, "General Purpose"},
+		{"m1.large", 16384, 2, "Memory Optimized"},
+	} {
+		id := fmt.Sprintf("a5d8c5e0-0000-4000-8000-%012d", i+1)
+		f.packages[id] = &compute.Package{
+			ID:      id,
+			Name:    spec.name,
+			Memory:  spec.memory,
+			Disk:    spec.memory * 20,
+			Swap:    spec.memory * 2,
+			LWPs:    4000,
+			VCPUs:   spec.vcpus,
+			Version: "1.0.0",
+			Group:   spec.group,
+		}
+	}
+
+	for _, image := range []*compute.Image{
+		{
+			ID:          fakeImageBase64LTSOldID,
+			Name:        "base-64-lts",
+			Version:     "23.4.0",
+			OS:          "smartos",
+			Type:        "zone-dataset",
+			Description: "A 64-bit SmartOS image with just essential packages installed.",
+			PublishedAt: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC),
+			Owner:       fakeImageOwner,
+			Public:      true,
+			State:       "active",
+			Tags:        map[string]string{"role": "os"},
+		},
+		{
+			ID:          fakeImageBase64LTSID,
+			Name:        "base-64-lts",
+			Version:     "24.4.0",
+			OS:          "smartos",
+			Type:        "zone-dataset",
+			Description: "A 64-bit SmartOS image with just essential packages installed.",
+			Homepage:    "https://docs.tritondatacenter.com/public-cloud/instances/infrastructure/images",
+			PublishedAt: time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
+			Owner:       fakeImageOwner,
+			Public:      true,
+			State:       "active",
+			Tags:        map[string]string{"role": "os"},
+			Files: []*compute.ImageFile{
+				{Compression: "gzip", SHA1: "7f0c1a4cbd5d8b2e5a1c7d1f3e2b4a6c8d0e2f41", Size: 136478213},
+			},
+		},
+		{
+			ID:           fakeImageUbuntuID,
+			Name:         "ubuntu-24.04",
+			Version:      "20250407",
+			OS:           "linux",
+			Type:         "zvol",
+			Description:  "Ubuntu 24.04 LTS (20250407 64-bit).",
+			PublishedAt:  time.Date(2025, 4, 7, 0, 0, 0, 0, time.UTC),
+			Owner:        fakeImageOwner,
+			Public:       true,
+			State:        "active",
+			Requirements: map[string]interface{}{"min_ram": float64(1024), "max_ram": float64(65536)},
+		},
+		{
+			ID:          fakeImageSharedID,
+			Name:        "partner-base",
+			Version:     "2.1.0",
+			OS:          "smartos",
+			Type:        "zone-dataset",
+			Description: "A private image shared with this account by a partner.",
+			PublishedAt: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+			Owner:       fakePartnerAccountID,
+			State:       "active",
+			ACL:         []string{fakeAccountID},
+		},
+	} {
+		f.images[image.ID] = image
+	}
+}
+
+func (f *fakeCloudAPI) registerRoutes() {
+	f.handle(http.MethodGet, "", f.getAccount)
+	f.handle(http.MethodGet, "datacenters", f.listDatacenters)
+
+	f.handle(http.MethodGet, "keys", f.listKeys)
+	f.handle(http.MethodPost, "keys", f.createKey)
+	f.handle(http.MethodGet, "keys/*", f.getKey)
+	f.handle(http.MethodDelete, "keys/*", f.deleteKey)
+
+	f.handle(http.MethodGet, "packages", f.listPackages)
+	f.handle(http.MethodGet, "packages/*", f.getPackage)
+
+	f.handle(http.MethodGet, "images", f.listImages)
+	f.handle(http.MethodPost, "images", f.createImage)
+	f.handle(http.MethodGet, "images/*", f.getImage)
+	f.handle(http.MethodPost, "images/*", f.imageAction)
+	f.handle(http.MethodDelete, "images/*", f.deleteImage)
+
+	f.handle(http.MethodGet, "machines", f.listMachines)
+	f.handle(http.MethodPost, "machines", f.createMachine)
+	f.handle(http.MethodGet, "machines/*", f.getMachine)
+	f.handle(http.MethodPost, "machines/*", f.machineAction)
+	f.handle(http.MethodDelete, "machines/*", f.deleteMachine)
+	f.handle(http.MethodGet, "machines/*/tags", f.listMachineTags)
+	f.handle(http.MethodPost, "machines/*/tags", f.addMachineTags)
+	f.handle(http.MethodPut, "machines/*/tags", f.replaceMachineTags)
+	f.handle(http.MethodDelete, "machines/*/tags", f.deleteMachineTags)
+	f.handle(http.MethodDelete, "machines/*/tags/*", f.deleteMachineTag)
+	f.handle(http.MethodGet, "machines/*/metadata", f.listMachineMetadata)
+	f.handle(http.MethodPost, "machines/*/metadata", f.updateMachineMetadata)
+	f.handle(http.MethodDelete, "machines/*/metadata/*", f.deleteMachineMetadata)
+	f.handle(http.MethodGet, "machines/*/nics", f.listNICs)
+	f.handle(http.MethodPost, "machines/*/nics", f.addNIC)
+	f.handle(http.MethodGet, "machines/*/nics/*", f.getNIC)
+	f.handle(http.MethodDelete, "machines/*/nics/*", f.removeNIC)
+	f.handle(http.MethodGet, "machines/*/snapshots", f.listSnapshots)
+	f.handle(http.MethodPost, "machines/*/snapshots", f.createSnapshot)
+	f.handle(http.MethodGet, "machines/*/snapshots/*", f.getSnapshot)
+	f.handle(http.MethodPost, "machines/*/snapshots/*", f.startFromSnapshot)
+	f.handle(http.MethodDelete, "machines/*/snapshots/*", f.deleteSnapshot)
+	f.handle(http.MethodGet, "machines/*/fwrules", f.listMachineRules)
+
+	f.handle(http.MethodGet, "volumes", f.listVolumes)
+	f.handle(http.MethodPost, "volumes", f.createVolume)
+	f.handle(http.MethodGet, "volumes/*", f.getVolume)
+	f.handle(http.MethodPost, "volumes/*", f.updateVolume)
+	f.handle(http.MethodDelete, "volumes/*", f.deleteVolume)
+	f.handle(http.MethodGet, "volumesizes", f.listVolumeSizes)
+
+	f.handle(http.MethodGet, "networks", f.listNetworks)
+	f.handle(http.MethodGet, "networks/*", f.getNetwork)
+	f.handle(http.MethodGet, "networks/*/ips", f.listNetworkIPs)
+	f.handle(http.MethodGet, "networks/*/ips/*", f.getNetworkIP)
+	f.handle(http.MethodPut, "networks/*/ips/*", f.updateNetworkIP)
+
+	f.handle(http.MethodGet, "fabrics/default/vlans", f.listVLANs)
+	f.handle(http.MethodPost, "fabrics/default/vlans", f.createVLAN)
+	f.handle(http.MethodGet, "fabrics/default/vlans/*", f.getVLAN)
+	f.handle(http.MethodPut, "fabrics/default/vlans/*", f.updateVLAN)
+	f.handle(http.MethodDelete, "fabrics/default/vlans/*", f.deleteVLAN)
+	f.handle(http.MethodGet, "fabrics/default/vlans/*/networks", f.listFabrics)
+	f.handle(http.MethodPost, "fabrics/default/vlans/*/networks", f.createFabric)
+	f.handle(http.MethodGet, "fabrics/default/vlans/*/networks/*", f.getFabric)
+	f.handle(http.MethodPut, "fabrics/default/vlans/*/networks/*", f.updateFabric)
+	f.handle(http.MethodDelete, "fabrics/default/vlans/*/networks/*", f.deleteFabric)
+
+	f.handle(http.MethodGet, "fwrules", f.listRules)
+	f.handle(http.MethodPost, "fwrules", f.createRule)
+	f.handle(http.MethodGet, "fwrules/*", f.getRule)
+	f.handle(http.MethodPost, "fwrules/*", f.updateRule)
+	f.handle(http.MethodDelete, "fwrules/*", f.deleteRule)
+	f.handle(http.MethodPost, "fwrules/*/enable", f.enableRule)
+	f.handle(http.MethodPost, "fwrules/*/disable", f.disableRule)
+	f.handle(http.MethodGet, "fwrules/*/machines", f.listRuleMachines)
+}
+
+func (f *fakeCloudAPI) handle(method, pattern string, handler func(w http.ResponseWriter, r *http.Request, args []string)) {
+	var parts []string
+	if pattern != "" {
+		parts = strings.Split(pattern, "/")
+	}
+	f.routes = append(f.routes, fakeRoute{
+		method:  method,
+		pattern: parts,
+		handler: handler,
+	})
+}
+
+// ServeHTTP authenticates the request, then dispatches it to the matching
+// route while holding the state lock.
+func (f *fakeCloudAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := f.authenticate(r); err != nil {
+		fakeError(w, http.StatusUnauthorized, "InvalidCredentials", err.Error())
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if parts[0] != f.Account {
+		fakeError(w, http.StatusForbidden, "NotAuthorized", fmt.Sprintf("%s is not allowed to access %s", f.Account, r.URL.Path))
+		return
+	}
+	parts = parts[1:]
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests = append(f.requests, r.Method+" "+strings.Join(parts, "/"))
+
+	pathFound := false
+	for _, route := range f.routes {
+		args, ok := fakeMatch(route.pattern, parts)
+		if !ok {
+			continue
+		}
+		pathFound = true
+		if route.method == r.Method {
+			route.handler(w, r, args)
+			return
+		}
+	}
+
+	if pathFound {
+		fakeError(w, http.StatusMethodNotAllowed, "BadMethod", fmt.Sprintf("%s is not allowed on %s", r.Method, r.URL.Path))
+		return
+	}
+	fakeError(w, http.StatusNotFound, "ResourceNotFound", fmt.Sprintf("%s does not exist", r.URL.Path))
+}
+
+var fakeAuthParamRegexp = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// authenticate verifies the http-signature scheme used by CloudAPI: the key
+// must belong to the account and the signature must cover the date header.
+func (f *fakeCloudAPI) authenticate(r *http.Request) error {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Signature ") {
+		return fmt.Errorf("missing http-signature authorization header")
+	}
+
+	params := map[string]string{}
+	for _, m := range fakeAuthParamRegexp.FindAllStringSubmatch(header, -1) {
+		params[m[1]] = m[2]
+	}
+
+	keyID := strings.Split(strings.Trim(params["keyId"], "/"), "/")
+	if len(keyID) < 3 || keyID[0] != f.Account || keyID[len(keyID)-2] != "keys" {
+		return fmt.Errorf("invalid keyId %q", params["keyId"])
+	}
+	if keyID[len(keyID)-1] != f.KeyID {
+		return fmt.Errorf("unknown key %q", keyID[len(keyID)-1])
+	}
+
+	var hash crypto.Hash
+	switch params["algorithm"] {
+	case "rsa-sha1":
+		hash = crypto.SHA1
+	case "rsa-sha256":
+		hash = crypto.SHA256
+	case "rsa-sha512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported signature algorithm %q", params["algorithm"])
+	}
+
+	headers := strings.Fields(params["headers"])
+	if len(headers) == 0 {
+		headers = []string{"date"}
+	}
+	var lines []string
+	for _, name := range headers {
+		value := r.Header.Get(name)
+		if value == "" {
+			return fmt.Errorf("signed header %q is missing", name)
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", strings.ToLower(name), value))
+	}
+
+	date, err := time.Parse(time.RFC1123, r.Header.Get("Date"))
+	if err != nil {
+		return fmt.Errorf("invalid date header: %s", err)
+	}
+	if skew := time.Since(date); skew > 5*time.Minute || skew < -5*time.Minute {
+		return fmt.Errorf("date header is too far from the current time")
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(params["signature"])
+	if err != nil {
+		return fmt.Errorf("invalid signature encoding: %s", err)
+	}
+
+	h := hash.New()
+	h.Write([]byte(strings.Join(lines, "\n")))
+	if err := rsa.VerifyPKCS1v15(f.publicKey, hash, h.Sum(nil), signature); err != nil {
+		return fmt.Errorf("invalid signature: %s", err)
+	}
+
+	return nil
+}
+
+func fakeMatch(pattern, parts []string) ([]string, bool) {
+	if len(parts) == 1 && parts[0] == "" {
+		parts = nil
+	}
+	if len(pattern) != len(parts) {
+		return nil, false
+	}
+
+	var args []string
+	for i, p := range pattern {
+		switch {
+		case p == "*":
+			args = append(args, parts[i])
+		case p != parts[i]:
+			return nil, false
+		}
+	}
+
+	return args, true
+}
+
+func fakeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if v != nil {
+		json.NewEncoder(w).Encode(v)
+	}
+}
+
+func fakeError(w http.ResponseWriter, status int, code, message string) {
+	fakeJSON(w, status, map[string]string{
+		"code":    code,
+		"message": message,
+	})
+}
+
+func fakeDecode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if r.Body == nil || r.ContentLength == 0 {
+		return true
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		fakeError(w, http.StatusBadRequest, "InvalidArgument", fmt.Sprintf("invalid JSON body: %s", err))
+		return false
+	}
+	return true
+}
+
+func (f *fakeCloudAPI) newUUID() string {
+	f.serial++
+	b := make([]byte, 16)
+	rand.Read(b[:12])
+	binary.BigEndian.PutUint32(b[12:], uint32(f.serial))
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func (f *fakeCloudAPI) getAccount(w http.ResponseWriter, r *http.Request, _ []string) {
+	fakeJSON(w, http.StatusOK, account.Account{
+		ID:               fakeAccountID,
+		Login:            f.Account,
+		Email:            f.Account + "@example.com",
+		TritonCNSEnabled: true,
+	})
+}
+
+func (f *fakeCloudAPI) listDatacenters(w http.ResponseWriter, r *http.Request, _ []string) {
+	fakeJSON(w, http.StatusOK, map[string]string{
+		"fake-1": f.URL(),
+	})
+}
+
+// Keys
+
+func (f *fakeCloudAPI) listKeys(w http.ResponseWriter, r *http.Request, _ []string) {
+	result := []*account.Key{}
+	for _, key := range f.keys {
+		result = append(result, key)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	fakeJSON(w, http.StatusOK, result)
+}
+
+func (f *fakeCloudAPI) createKey(w http.ResponseWriter, r *http.Request, _ []string) {
+	var input account.CreateKeyInput
+	if !fakeDecode(w, r, &input) {
+		return
+	}
+
+	publicKey, comment, _, _, err := ssh.ParseAuthorizedKey([]byte(input.Key))
+	if err != nil {
+		fakeError(w, http.StatusConflict, "InvalidArgument", fmt.Sprintf("key is invalid: %s", err))
+		return
+	}
+	if input.Name == "" {
+		input.Name = comment
+	}
+	if _, found := f.keys[input.Name]; found {
+		fakeError(w, http.StatusConflict, "InvalidArgument", fmt.Sprintf("key %q already exists", input.Name))
+		return
+	}
+
+	key := &account.Key{
+		Name:        input.Name,
+		Fingerprint: strings.TrimPrefix(ssh.FingerprintLegacyMD5(publicKey), "MD5:"),
+		Key:         input.Key,
+	}
+	f.keys[key.Name] = key
+	fakeJSON(w, http.StatusCreated, key)
+}
+
+func (f *fakeCloudAPI) findKey(name string) *account.Key {
+	if key, found := f.keys[name]; found {
+		return key
+	}
+	for _, key := range f.keys {
+		if key.Fingerprint == name {
+			return key
+		}
+	}
+	return nil
+}
+
+func (f *fakeCloudAPI) getKey(w http.ResponseWriter, r *http.Request, args []string) {
+	key := f.findKey(args[0])
+	if key == nil {
+		fakeError(w, http.StatusNotFound, "ResourceNotFound", fmt.Sprintf("key %s not found", args[0]))
+		return
+	}
+	fakeJSON(w, http.StatusOK, key)
+}
+
+func (f *fakeCloudAPI) deleteKey(w http.ResponseWriter, r *http.Request, args []string) {
+	key := f.findKey(args[0])
+	if key == nil {
+		fakeError(w, http.StatusNotFound, "ResourceNotFound", fmt.Sprintf("key %s not found", args[0]))
+		return
+	}
+	delete(f.keys, key.Name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Packages
+
+func (f *fakeCloudAPI) listPackages(w http.ResponseWriter, r *http.Request, _ []string) {
+	query := r.URL.Query()
+	result := []*compute.Package{}
+	for _, pkg := range f.packages {
+		if !fakeQueryMatches(query, "name", pkg.Name) ||
+			!fakeQueryMatches(query, "version", pkg.Version) ||
+			!fakeQueryMatches(query, "group", pkg.Group) ||
+			!fakeQueryMatches(query, "brand", pkg.Brand) ||
+			!fakeQueryMatches(query, "memory", strconv.FormatInt(pkg.Memory, 10)) ||
+			!fakeQueryMatches(query, "disk", strconv.FormatInt(pkg.Disk, 10)) ||
+			!fakeQueryMatches(query, "swap", strconv.FormatInt(pkg.Swap, 10)) ||
+			!fakeQueryMatches(query, "lwps", strconv.FormatInt(pkg.LWPs, 10)) ||
+			!fakeQueryMatches(query, "vcpus", strconv.FormatInt(pkg.VCPUs, 10)) {
+			continue
+		}
+		result = append(result, pkg)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Memory < result[j].Memory })
+	fakeJSON(w, http.StatusOK, result)
+}
+
+func (f *fakeCloudAPI) findPackage(nameOrID string) *compute.Package {
+	if pkg, found := f.packages[nameOrID]; found {
+		return pkg
+	}
+	for _, pkg := range f.packages {
+		if pkg.Name == nameOrID {
+			return pkg
+		}
+	}
+	return nil
+}
+
+func (f *fakeCloudAPI) getPackage(w http.ResponseWriter, r *http.Request, args []string) {
+	pkg := f.findPackage(args[0])
+	if pkg == nil {
+		fakeError(w, http.StatusNotFound, "ResourceNotFound", fmt.Sprintf("package %s not found", args[0]))
+		return
+	}
+	fakeJSON(w, http.StatusOK, pkg)
+}
+
+// fakeQueryMatches reports whether the value satisfies the query parameter
+// of the given name. Missing parameters always match.
+func fakeQueryMatches(query map[string][]string, name, value string) bool {
+	if want, found := query[name]; found && len(want) > 0 && want[0] != "" {
+		return want[0] == value
+	}
+	return true
+}
+
+// Images
+
+func (f *fakeCloudAPI) listImages(w http.ResponseWriter, r *http.Request, _ []string) {
+	query := r.URL.Query()
+	state := query.Get("state")
+	if state == "" {
+		state = "active"
+	}
+
+	result := []*compute.Image{}
+	for _, image := range f.images {
+		if !fakeQueryMatches(query, "name", image.Name) ||
+			!fakeQueryMatches(query, "os", image.OS) ||
+			!fakeQueryMatches(query, "version", image.Version) ||
+			!fakeQueryMatches(query, "owner", image.Owner) ||
+			!fakeQueryMatches(query, "type", image.Type) {
+			continue
+		}
+		if state != "all" && image.State != state {
+			continue
+		}
+		if query.Get("public") == "true" && !image.Public {
+			continue
+		}
+		result = append(result, image)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].PublishedAt.Before(result[j].PublishedAt) })
+	fakeJSON(w, http.StatusOK, result)
+}
+
+func (f *fakeCloudAPI) getImage(w http.ResponseWriter, r *http.Request, args []string) {
+	image, found := f.images[args[0]]
+	if !found {
+		fakeError(w, http.StatusNotFound, "ResourceNotFound", fmt.Sprintf("image %s not found", args[0]))
+		return
+	}
+	fakeJSON(w, http.StatusOK, image)
+}
+
+func (f *fakeCloudAPI) createImage(w http.ResponseWriter, r *http.Request, _ []string) {
+	var input compute.CreateImageFromMachineInput
+	if !fakeDecode(w, r, &input) {
+		return
+	}
+	if input.Name == "" || input.Version == "" {
+		fakeError(w, http.StatusConflict, "MissingParameter", "name and version are required")
+		return
+	}
+	m, found := f.machines[input.MachineID]
+	if !found || m.State == machineStateDeleted {
+		fakeError(w, http.StatusConflict, "InvalidArgument", fmt.Sprintf("VM %q not found", input.MachineID))
+		return
+	}
+	for _, image := range f.images {
+		if image.Owner == fakeAccountID && image.Name == input.Name && image.Version == input.Version {
+			fakeError(w, http.StatusConflict, "ImageUuidAlreadyExists", fmt.Sprintf("image %s@%s already exists", input.Name, input.Version))
+			return
+		}
+	}
+
+	origin := f.images[m.Image]
+	image := &compute.Image{
+		ID:          f.newUUID(),
+		Name:        input.Name,
+		Version:     input.Version,
+		Description: input.Description,
+		Homepage:    input.HomePage,
+		EULA:        input.EULA,
+		ACL:         input.ACL,
+		Tags:        input.Tags,
+		OS:          origin.OS,
+		Type:        origin.Type,
+		PublishedAt: time.Now().UTC(),
+		Owner:       fakeAccountID,
+		State:       "active",
+	}
+	f.images[image.ID] = image
+
+	response := *image
+	response.State = "creating"
+	fakeJSON(w, http.StatusCreated, &response)
+}
+
+func (f *fakeCloudAPI) imageAction(w http.ResponseWriter, r *http.Request, args []string) {
+	image, found := f.images[args[0]]
+	if !found {
+		fakeError(w, http.StatusNotFound, "ResourceNotFound", fmt.Sprintf("image %s not found", args[0]))
+		return
+	}
+
+	switch action := r.URL.Query().Get("action"); action {
+	case "update":
+		if image.Owner != fakeAccountID {
+			fakeError(w, http.StatusForbidden, "NotAuthorized", fmt.Sprintf("image %s is not owned by %s", image.ID, f.Account))
+			return
+		}
+		var input struct {
+			Name        *string           `json:"name"`
+			Version     *string           `json:"version"`
+			Description *string           `json:"description"`
+			Homepage    *string           `json:"homepage"`
+			EULA        *string           `json:"eula"`
+			ACL         []string          `json:"acl"`
+			Tags        map[string]string `json:"tags"`
+		}
+		if !fakeDecode(w, r, &input) {
+			return
+		}
+		for _, field := range []struct {
+			value  *string
+			target *string
+		}{
+			{input.Name, &image.Name},
+			{input.Version, &image.Version},
+			{input.Description, &image.Description},
+			{input.Homepage, &image.Homepage},
+			{input.EULA, &image.EULA},
+		} {
+			if field.value != nil {
+				*field.target = *field.value
+			}
+		}
+		if input.ACL != nil {
+			image.ACL = input.ACL
+		}
+		if input.Tags != nil {
+			image.Tags = input.Tags
+		}
+		fakeJSON(w, http.StatusOK, image)
+	case "share", "unshare":
+		if image.Owner != fakeAccountID || image.Public {
+			fakeError(w, http.StatusForbidden, "NotAuthorized", fmt.Sprintf("image %s is not a private image owned by %s", image.ID, f.Account))
+			return
+		}
+		var input struct {
+			Account string `json:"account"`
+		}
+		if !fakeDecode(w, r, &input) {
+			return
+		}
+		if input.Account == "" {
+			input.Account = r.URL.Query().Get("account")
+		}
+		if input.Account == "" {
+			fakeError(w, http.StatusConflict, "MissingParameter", "account is required")
+			return
+		}
+		acl := []string{}
+		for _, account := range image.ACL {
+			if account != input.Account {
+				acl = append(acl, account)
+			}
+		}
+		if action == "share" {
+			acl = append(acl, input.Account)
+		}
+		image.ACL = acl
+		fakeJSON(w, http.StatusOK, image)
+	case "clone":
+		shared := false
+		for _, account := range image.ACL {
+			shared = shared || account == fakeAccountID
+		}
+		if image.Owner == fakeAccountID || !shared {
+			fakeError(w, http.StatusConflict, "InvalidArgument", fmt.Sprintf("image %s is not shared with %s", image.ID, f.Account))
+			return
+		}
+		clone := *image
+		clone.ID = f.newUUID()
+		clone.Owner = fakeAccountID
+		clone.ACL = nil
+		clone.PublishedAt = time.Now().UTC()
+		f.images[clone.ID] = &clone
+		fakeJSON(w, http.StatusOK, &clone)
+	case "export":
+		if image.Owner != fakeAccountID {
+			fakeError(w, http.StatusForbidden, "NotAuthorized", fmt.Sprintf("image %s is not owned by %s", image.ID, f.Account))
+			return
+		}
+		mantaPath := r.URL.Query().Get("manta_path")
+		if mantaPath == "" {
+			fakeError(w, http.StatusConflict, "MissingParameter", "manta_path is required")
+			return
+		}
+		if strings.HasSuffix(mantaPath, "/") {
+			mantaPath += fmt.Sprintf("%s-%s", image.Name, image.Version)
+		}
+		fakeJSON(w, http.StatusOK, compute.MantaLocation{
+			MantaURL:     "https://manta.example.com",
+			ImagePath:    mantaPath + ".zfs.gz",
+			ManifestPath: mantaPath + ".imgmanifest",
+		})
+	default:
+		fakeError(w, http.StatusConflict, "InvalidArgument", fmt.Sprintf("unsupported action %q", action))
+	}
+}
+
+func (f *fakeCloudAPI) deleteImage(w http.ResponseWriter, r *http.Request, args []string) {
+	image, found := f.images[args[0]]
+	if !found {
+		fakeError(w, http.StatusNotFound, "ResourceNotFound", fmt.Sprintf("image %s not found", args[0]))
+		return
+	}
+	if image.Owner == fakeImageOwner {
+		fakeError(w, http.StatusForbidden, "NotAuthorized", fmt.Sprintf("image %s is not owned by %s", image.ID, f.Account))
+		return
+	}
+	delete(f.images, image.ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Machines
+
+func (f *fakeCloudAPI) listMachines(w http.ResponseWriter, r *http.Request, _ []string) {
+	query := r.URL.Query()
+	result := []*fakeMachine{}
+	for _, m := range f.machines {
+		if m.State == machineStateDeleted {
+			continue
+		}
+		if !fakeQueryMatches(query, "name", m.Name) ||
+			!fakeQueryMatches(query, "image", m.Image) ||
+			!fakeQueryMatches(query, "state", m.State) ||
+			!fakeQueryMatches(query, "brand", m.Brand) {
+			continue
+		}
+		tagged := true
+		for k, v := range query {
+			if strings.HasPrefix(k, "tag.") && fmt.Sprint(m.Tags[strings.TrimPrefix(k, "tag.")]) != v[0] {
+				tagged = false
+			}
+		}
+		if tagged {
+			result = append(result, m)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Created.Before(result[j].Created) })
+	fakeJSON(w, http.StatusOK, result)
+}
+
+// machine looks up a machine by ID and writes an error response if it does
+// not exist or has been deleted.
+func (f *fakeCloudAPI) machine(w http.ResponseWriter, id string) *fakeMachine {
+	m, found := f.machines[id]
+	if !found {
+		fakeError(w, http.StatusNotFound, "ResourceNotFound", fmt.Sprintf("VM %s not found", id))
+		return nil
+	}
+	if m.State == machineStateDeleted {
+		fakeJSON(w, http.StatusGone, m)
+		return nil
+	}
+	return m
+}
+
+func (f *fakeCloudAPI) createMachine(w http.ResponseWriter, r *http.Request, _ []string) {
+	var input map[string]interface{}
+	if !fakeDecode(w, r, &input) {
+		return
+	}
+
+	imageID, _ := input["image"].(string)
+	image, found := f.images[imageID]
+	if !found {
+		fakeError(w, http.StatusConflict, "InvalidArgument", fmt.Sprintf("image %q not found", imageID))
+		return
+	}
+	packageName, _ := input["package"].(string)
+	pkg := f.findPackage(packageName)
+	if pkg == nil {
+		fakeError(w, http.StatusConflict, "InvalidArgument", fmt.Sprintf("package %q not found", packageName))
+		return
+	}
+
+	now := time.Now().UTC()
+	m := &fakeMachine{
+		ID:              f.newUUID(),
+		State:           machineStateRunning,
+		Image:           image.ID,
+		Memory:          int(pkg.Memory),
+		Disk:            int(pkg.Disk),
+		Metadata:        map[string]string{},
+		Tags:            map[string]interface{}{},
+		Created:         now,
+		Updated:         now,
+		ComputeNode:     "44454c4c-5400-1034-8052-b5c04f383432",
+		Package:         pkg.Name,
+		FirewallEnabled: input["firewall_enabled"] == true,
+		DelegateDataset: input["delegate_dataset"] == true,
+	}
+	switch image.Type {
+	case "zvol":
+		m.Type, m.Brand = "virtualmachine", "kvm"
+	case "lx-dataset":
+		m.Type, m.Brand = "smartmachine", "lx"
+	default:
+		m.Type, m.Brand = "smartmachine", "joyent"
+	}
+
+	m.Name, _ = input["name"].(string)
+	if m.Name == "" {
+		m.Name = m.ID[:8]
+	}
+	for k, v := range input {
+		switch {
+		case strings.HasPrefix(k, "tag."):
+			m.Tags[strings.TrimPrefix(k, "tag.")] = v
+		case strings.HasPrefix(k, "metadata."):
+			m.Metadata[strings.TrimPrefix(k, "metadata.")] = fmt.Sprint(v)
+		}
+	}
+
+	var requested []compute.NetworkObject
+	if raw, found := input["networks"]; found {
+		b, _ := json.Marshal(raw)
+		if err := json.Unmarshal(b, &requested); err != nil {
+			fakeError(w, http.StatusConflict, "InvalidArgument", fmt.Sprintf("invalid networks: %s", err))
+			return
+		}
+	}
+	if len(requested) == 0 {
+		requested = []compute.NetworkObject{{IPv4UUID: fakePublicNetworkID}}
+	}
+
+	var nics []*compute.NIC
+	for _, object := range requested {
+		nic, err := f.newNIC(m.ID, object)
+		if err != nil {
+			for _, nic := range nics {
+				f.releaseNIC(nic)
+			}
+			fakeError(w, http.StatusConflict, "InvalidArgument", err.Error())
+			return
+		}
+		nics = append(nics, nic)
+	}
+	nics[0].Primary = true
+	m.nics = nics
+
+	f.machines[m.ID] = m
+	f.refreshMachine(m)
+
+	response := *m
+	response.State = machineStateProvisioning
+	fakeJSON(w, http.StatusCreated, &response)
+}
+
+func (f *fakeCloudAPI) getMachine(w http.ResponseWriter, r *http.Request, args []string) {
+	if m := f.machine(w, args[0]); m != nil {
+		fakeJSON(w, http.StatusOK, m)
+
+		// A reboot is only picked up after the first poll, which still
+		// sees the machine running; the next poll sees it going down, and
+		// the one after that running again.
+		if m.rebootPolls > 0 {
+			m.rebootPolls--
+			m.State = machineStateRunning
+			if m.rebootPolls == 1 {
+				m.State = machineStateStopping
+			}
+			m.Updated = time.Now().UTC()
+		}
+	}
+}
+
+func (f *fakeCloudAPI) machineAction(w http.ResponseWriter, r *http.Request, args []string) {
+	m := f.machine(w, args[0])
+	if m == nil {
+		return
+	}
+
+	query := r.URL.Query()
+	switch action := query.Get("action"); action {
+	case "rename":
+		m.Name = query.Get("name")
+	case "resize":
+		pkg := f.findPackage(query.Get("package"))
+		if pkg == nil {
+			fakeError(w, http.StatusConflict, "InvalidArgument", fmt.Sprintf("package %q not found", query.Get("package")))
+			return
+		}
+		m.Package = pkg.Name
+		m.Memory = int(pkg.Memory)
+		m.Disk = int(pkg.Disk)
+	case "enable_firewall":
+		m.FirewallEnabled = true
+	case "disable_firewall":
+		m.FirewallEnabled = false
+	case "enable_deletion_protection":
+		m.DeletionProtection = true
+	case "disable_deletion_protection":
+		m.DeletionProtection = false
+	case "start":
+		m.State = machineStateRunning
+	case "reboot":
+		if m.State != machineStateRunning {
+			fakeError(w, http.StatusConflict, "InvalidState", fmt.Sprintf("VM %s is not running", m.ID))
+			return
+		}
+		m.reboots++
+		m.rebootPolls = 2
+		w.WriteHeader(http.StatusAccepted)
+		return
+	case "stop":
+		m.State = machineStateStopped
+	default:
+		fakeError(w, http.StatusConflict, "InvalidArgument", fmt.Sprintf("unsupported action %q", action))
+		return
+	}
+
+	f.refreshMachine(m)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (f *fakeCloudAPI) deleteMachine(w http.ResponseWriter, r *http.Request, args []string) {
+	m := f.machine(w, args[0])
+	if m == nil {
+		return
+	}
+	if m.DeletionProtection {
+		fakeError(w, http.StatusConflict, "CannotDestroyMachine", "deletion protection is enabled for this instance")
+		return
+	}
+
+	for _, nic := range m.nics {
+		f.releaseNIC(nic)
+	}
+	m.nics = nil
+	m.snapshots = nil
+	m.State = machineStateDeleted
+	f.refreshMachine(m)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// refreshMachine recomputes the attributes which CloudAPI derives from the
+// NICs, tags and CNS configuration of a machine.
+func (f *fakeCloudAPI) refreshMachine(m *fakeMachine) {
+	m.Updated = time.Now().UTC()
+	m.IPs = []string{}
+	m.Networks = []string{}
+	m.PrimaryIP = ""
+	for _, nic := range m.nics {
+		m.IPs = append(m.IPs, nic.IP)
+		m.Networks = append(m.Networks, nic.Network)
+		if n, found := f.networks[nic.Network]; found && n.Public && m.PrimaryIP == "" {
+			m.PrimaryIP = nic.IP
+		}
+	}
+	if m.PrimaryIP == "" {
+		for _, nic := range m.nics {
+			if nic.Primary {
+				m.PrimaryIP = nic.IP
+			}
+		}
+	}
+
+	m.DomainNames = []string{}
+	if disable, _ := m.Tags[compute.CNSTagDisable].(bool); disable || m.State == machineStateDeleted {
+		return
+	}
+	zone := fmt.Sprintf("%s.fake-1.cns.example.com", f.Account)
+	m.DomainNames = append(m.DomainNames,
+		fmt.Sprintf("%s.inst.%s", m.ID, zone),
+		fmt.Sprintf("%s.inst.%s", m.Name, zone))
+	if services, ok := m.Tags[compute.CNSTagServices].(string); ok && services != "" {
+		for _, service := range strings.Split(services, ",") {
+			m.DomainNames = append(m.DomainNames,
+				fmt.Sprintf("%s.svc.%s", strings.Split(service, ":")[0], zone))
+		}
+	}
+}
+
+func (f *fakeCloudAPI) listMachineTags(w http.ResponseWriter, r *http.Request, args []string) {
+	if m := f.machine(w, args[0]); m != nil {
+		fakeJSON(w, http.StatusOK, m.Tags)
+	}
+}
+
+func (f *fakeCloudAPI) addMachineTags(w http.ResponseWriter, r *http.Request, args []string) {
+	m := f.machine(w, args[0])
+	if m == nil {
+		return
+	}
+	var tags map[string]interface{}
+	if !fakeDecode(w, r, &tags) {
+		return
+	}
+	for k, v := range tags {
+		m.Tags[k] = v
+	}
+	f.refreshMachine(m)
+	fakeJSON(w, http.StatusOK, m.Tags)
+}
+
+func (f *fakeCloudAPI) replaceMachineTags(w http.ResponseWriter, r *http.Request, args []string) {
+	m := f.machine(w, args[0])
+	if m == nil {
+		return
+	}
+	tags := map[string]interface{}{}
+	if !fakeDecode(w, r, &tags) {
+		return
+	}
+	m.Tags = tags
+	f.refreshMachine(m)
+	fakeJSON(w, http.StatusOK, m.Tags)
+}
+
+func (f *fakeCloudAPI) deleteMachineTags(w http.ResponseWriter, r *http.Request, args []string) {
+	if m := f.machine(w, args[0]); m != nil {
+		m.Tags = map[string]interface{}{}
+		f.refreshMachine(m)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (f *fakeCloudAPI) deleteMachineTag(w http.ResponseWriter, r *http.Request, args []string) {
+	if m := f.machine(w, args[0]); m != nil {
+		delete(m.Tags, args[1])
+		f.refreshMachine(m)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (f *fakeCloudAPI) listMachineMetadata(w http.ResponseWriter, r *http.Request, args []string) {
+	if m := f.machine(w, args[0]); m != nil {
+		fakeJSON(w, http.StatusOK, m.Metadata)
+	}
+}
+
+func (f *fakeCloudAPI) updateMachineMetadata(w http.ResponseWriter, r *http.Request, args []string) {
+	m := f.machine(w, args[0])
+	if m == nil {
+		return
+	}
+	var metadata map[string]string
+	if !fakeDecode(w, r, &metadata) {
+		return
+	}
+	for k, v := range metadata {
+		m.Metadata[k] = v
+	}
+	f.refreshMachine(m)
+	fakeJSON(w, http.StatusOK, m.Metadata)
+}
+
+func (f *fakeCloudAPI) deleteMachineMetadata(w http.ResponseWriter, r *http.Request, args []string) {
+	if m := f.machine(w, args[0]); m != nil {
+		delete(m.Metadata, args[1])
+		f.refreshMachine(m)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// NICs
+
+// newNIC allocates an address on the requested network for the given owner.
+func (f *fakeCloudAPI) newNIC(owner string, object compute.NetworkObject) (*compute.NIC, error) {
+	n, found := f.networks[object.IPv4UUID]
+	if !found {
+		return nil, fmt.Errorf("network %q not found", object.IPv4UUID)
+	}
+	if len(n.Networks) > 0 {
+		// Pools allocate from the first member network with a free address.
+		pool := n
+		n = nil
+		for _, id := range pool.Networks {
+			if member := f.networks[id]; f.nextFreeIP(member) != "" {
+				n = member
+				break
+			}
+		}
+		if n == nil || len(object.IPv4IPs) > 0 {
+			return nil, fmt.Errorf("network pool %s cannot allocate an address", pool.Id)
+		}
+	}
+
+	var ip string
+	if len(object.IPv4IPs) > 0 {
+		ip = object.IPv4IPs[0]
+		if !fakeSubnetContains(n.Subnet, ip) {
+			return nil, fmt.Errorf("IP %s is not in subnet %s of network %s", ip, n.Subnet, n.Id)
+		}
+		if _, used := n.used[ip]; used {
+			return nil, fmt.Errorf("IP %s is already in use on network %s", ip, n.Id)
+		}
+	} else {
+		ip = f.nextFreeIP(n)
+		if ip == "" {
+			return nil, fmt.Errorf("network %s has no free IP addresses", n.Id)
+		}
+	}
+	n.used[ip] = owner
+
+	f.serial++
+	_, subnet, _ := net.ParseCIDR(n.Subnet)
+	return &compute.NIC{
+		IP:      ip,
+		MAC:     fmt.Sprintf("90:b8:d0:%02x:%02x:%02x", (f.serial>>16)&0xff, (f.serial>>8)&0xff, f.serial&0xff),
+		Netmask: net.IP(subnet.Mask).String(),
+		Gateway: n.Gateway,
+		State:   "running",
+		Network: n.Id,
+	}, nil
+}
+
+func (f *fakeCloudAPI) releaseNIC(nic *compute.NIC) {
+	if n, found := f.networks[nic.Network]; found {
+		delete(n.used, nic.IP)
+	}
+}
+
+func (f *fakeCloudAPI) nextFreeIP(n *fakeNetwork) string {
+	start := fakeIPToUint(net.ParseIP(n.ProvisioningStartIP))
+	end := fakeIPToUint(net.ParseIP(n.ProvisioningEndIP))
+	for i := start; i <= end && i != 0; i++ {
+		ip := fakeUintToIP(i).String()
+		if _, used := n.used[ip]; !used && !n.reserved[ip] {
+			return ip
+		}
+	}
+	return ""
+}
+
+func fakeIPToUint(ip net.IP) uint32 {
+	if ip = ip.To4(); ip == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(ip)
+}
+
+func fakeUintToIP(v uint32) net.IP {
+	ip := make(net.IP, 4)
+	binary.BigEndian.PutUint32(ip, v)
+	return ip
+}
+
+func fakeSubnetContains(cidr, ip string) bool {
+	_, subnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return false
+	}
+	parsed := net.ParseIP(ip)
+	return parsed != nil && subnet.Contains(parsed)
+}
+
+func (f *fakeCloudAPI) findNIC(m *fakeMachine, mac string) (int, *compute.NIC) {
+	for i, nic := range m.nics {
+		if strings.ReplaceAll(nic.MAC, ":", "") == strings.ReplaceAll(mac, ":", "") {
+			return i, nic
+		}
+	}
+	return -1, nil
+}
+
+func (f *fakeCloudAPI) listNICs(w http.ResponseWriter, r *http.Request, args []string) {
+	if m := f.machine(w, args[0]); m != nil {
+		result := []*compute.NIC{}
+		result = append(result, m.nics...)
+		fakeJSON(w, http.StatusOK, result)
+	}
+}
+
+func (f *fakeCloudAPI) addNIC(w http.ResponseWriter, r *http.Request, args []string) {
+	m := f.machine(w, args[0])
+	if m == nil {
+		return
+	}
+	var input struct {
+		Network compute.NetworkObject `json:"network"`
+		Primary bool                  `json:"primary"`
+	}
+	if !fakeDecode(w, r, &input) {
+		return
+	}
+
+	nic, err := f.newNIC(m.ID, input.Network)
+	if err != nil {
+		fakeError(w, http.StatusConflict, "InvalidArgument", err.Error())
+		return
+	}
+	if input.Primary {
+		for _, other := range m.nics {
+			other.Primary = false
+		}
+		nic.Primary = true
+	}
+	m.nics = append(m.nics, nic)
+	f.refreshMachine(m)
+
+	response := *nic
+	response.State = "provisioning"
+	fakeJSON(w, http.StatusCreated, &response)
+}
+
+func (f *fakeCloudAPI) getNIC(w http.ResponseWriter, r *http.Request, args []string) {
+	m := f.machine(w, args[0])
+	if m == nil {
+		return
+	}
+	_, nic := f.findNIC(m, args[1])
+	if nic == nil {
+		fakeError(w, http.StatusNotFound, "ResourceNotFound", fmt.Sprintf("NIC %s not found", args[1]))
+		return
+	}
+	fakeJSON(w, http.StatusOK, nic)
+}
+
+func (f *fakeCloudAPI) removeNIC(w http.ResponseWriter, r *http.Request, args []string) {
+	m := f.machine(w, args[0])
+	if m == nil {
+		return
+	}
+	i, nic := f.findNIC(m, args[1])
+	if nic == nil {
+		fakeError(w, http.StatusNotFound, "ResourceNotFound", fmt.Sprintf("NIC %s not found", args[1]))
+		return
+	}
+
+	f.releaseNIC(nic)
+	m.nics = append(m.nics[:i], m.nics[i+1:]...)
+	if nic.Primary && len(m.nics) > 0 {
+		m.nics[0].Primary = true
+	}
+	f.refreshMachine(m)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Snapshots
+
+func (f *fakeCloudAPI) findSnapshot(m *fakeMachine, name string) *fakeSnapshot {
+	for _, snapshot := range m.snapshots {
+		if snapshot.Name == name {
+			return snapshot
+		}
+	}
+	return nil
+}
+
+func (f *fakeCloudAPI) listSnapshots(w http.ResponseWriter, r *http.Request, args []string) {
+	if m := f.machine(w, args[0]); m != nil {
+		result := []*fakeSnapshot{}
+		result = append(result, m.snapshots...)
+		fakeJSON(w, http.StatusOK, result)
+	}
+}
+
+func (f *fakeCloudAPI) createSnapshot(w http.ResponseWriter, r *http.Request, args []string) {
+	m := f.machine(w, args[0])
+	if m == nil {
+		return
+	}
+	if m.Brand == "kvm" {
+		fakeError(w, http.StatusConflict, "InvalidArgument", "snapshots are not supported for KVM instances")
+		return
+	}
+	var input struct {
+		Name string `json:"name"`
+	}
+	if !fakeDecode(w, r, &input) {
+		return
+	}
+	if input.Name == "" {
+		input.Name = time.Now().UTC().Format("20060102T150405Z")
+	}
+	if f.findSnapshot(m, input.Name) != nil {
+		fakeError(w, http.StatusConflict, "InvalidArgument", fmt.Sprintf("snapshot %q already exists", input.Name))
+		return
+	}
+
+	now := time.Now().UTC()
+	snapshot := &fakeSnapshot{
+		Name:    input.Name,
+		State:   "created",
+		Created: now,
+		Updated: now,
+	}
+	m.snapshots = append(m.snapshots, snapshot)
+
+	response := *snapshot
+	response.State = "queued"
+	fakeJSON(w, http.StatusCreated, &response)
+}
+
+func (f *fakeCloudAPI) getSnapshot(w http.ResponseWriter, r *http.Request, args []string) {
+	m := f.machine(w, args[0])
+	if m == nil {
+		return
+	}
+	snapshot := f.findSnapshot(m, args[1])
+	if snapshot == nil {
+		fakeError(w, http.StatusNotFound, "ResourceNotFound", fmt.Sprintf("snapshot %s not found", args[1]))
+		return
+	}
+	fakeJSON(w, http.StatusOK, snapshot)
+}
+
+func (f *fakeCloudAPI) startFromSnapshot(w http.ResponseWriter, r *http.Request, args []string) {
+	m := f.machine(w, args[0])
+	if m == nil {
+		return
+	}
+	if f.findSnapshot(m, args[1]) == nil {
+		fakeError(w, http.StatusNotFound, "ResourceNotFound", fmt.Sprintf("snapshot %s not found", args[1]))
+		return
+	}
+	if m.State != machineStateStopped {
+		fakeError(w, http.StatusConflict, "InvalidState", fmt.Sprintf("VM %s must be stopped to start it from a snapshot", m.ID))
+		return
+	}
+	m.rollbacks = append(m.rollbacks, args[1])
+	m.State = machineStateRunning
+	f.refreshMachine(m)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (f *fakeCloudAPI) deleteSnapshot(w http.ResponseWriter, r *http.Request, args []string) {
+	m := f.machine(w, args[0])
+	if m == nil {
+		return
+	}
+	for i, snapshot := range m.snapshots {
+		if snapshot.Name == args[1] {
+			m.snapshots = append(m.snapshots[:i], m.snapshots[i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	fakeError(w, http.StatusNotFound, "ResourceNotFound", fmt.Sprintf("snapshot %s not found", args[1]))
+}
+
+// Volumes
+
+func (f *fakeCloudAPI) listVolumes(w http.ResponseWriter, r *http.Request, _ []string) {
+	query := r.URL.Query()
+	result := []*compute.Volume{}
+	for _, volume := range f.volumes {
+		if !fakeQueryMatches(query, "name", volume.Name) ||
+			!fakeQueryMatches(query, "state", volume.State) ||
+			!fakeQueryMatches(query, "type", volume.Type) ||
+			!fakeQueryMatches(query, "size", strconv.FormatInt(volume.Size, 10)) {
+			continue
+		}
+		result = append(result, volume)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	fakeJSON(w, http.StatusOK, result)
+}
+
+func (f *fakeCloudAPI) createVolume(w http.ResponseWriter, r *http.Request, _ []string) {
+	var input struct {
+		Name     string            `json:"name"`
+		Size     int64             `json:"size"`
+		Type     string            `json:"type"`
+		Networks []string          `json:"networks"`
+		Tags     map[string]string `json:"tags"`
+	}
+	if !fakeDecode(w, r, &input) {
+		return
+	}
+	if input.Type == "" {
+		input.Type = "tritonnfs"
+	}
+	if input.Type != "tritonnfs" {
+		fakeError(w, http.StatusConflict, "InvalidArgument", fmt.Sprintf("unsupported volume type %q", input.Type))
+		return
+	}
+	if input.Size == 0 {
+		input.Size = 10240
+	}
+	if !slices.Contains(fakeVolumeSizes, input.Size) {
+		fakeError(w, http.StatusConflict, "InvalidArgument", fmt.Sprintf("unsupported volume size %d", input.Size))
+		return
+	}
+	if len(input.Networks) == 0 {
+		input.Networks = []string{fakeFabricNetworkID}
+	}
+	for _, id := range input.Networks {
+		if _, found := f.networks[id]; !found {
+			fakeError(w, http.StatusConflict, "InvalidArgument", fmt.Sprintf("network %q not found", id))
+			return
+		}
+	}
+
+	volume := &compute.Volume{
+		ID:       f.newUUID(),
+		Name:     input.Name,
+		Owner:    fakeAccountID,
+		Type:     input.Type,
+		Size:     input.Size,
+		State:    volumeStateReady,
+		Networks: input.Networks,
+		Refs:     []string{},
+		Tags:     input.Tags,
+	}
+	if volume.Name == "" {
+		volume.Name = "volume-" + volume.ID[:8]
+	}
+	for _, existing := range f.volumes {
+		if existing.Name == volume.Name {
+			fakeError(w, http.StatusConflict, "VolumeAlreadyExists", fmt.Sprintf("volume %q already exists", volume.Name))
+			return
+		}
+	}
+	volume.FileSystemPath = fmt.Sprintf("%s:/exports/data", f.nextFreeIP(f.networks[volume.Networks[0]]))
+	f.volumes[volume.ID] = volume
+
+	response := *volume
+	response.State = volumeStateCreating
+	fakeJSON(w, http.StatusCreated, &response)
+}
+
+func (f *fakeCloudAPI) volume(w http.ResponseWriter, id string) *compute.Volume {
+	volume, found := f.volumes[id]
+	if !found {
+		fakeError(w, http.StatusNotFound, "VolumeNotFound", fmt.Sprintf("volume %s not found", id))
+		return nil
+	}
+	return volume
+}
+
+func (f *fakeCloudAPI) getVolume(w http.ResponseWriter, r *http.Request, args []string) {
+	if volume := f.volume(w, args[0]); volume != nil {
+		fakeJSON(w, http.StatusOK, volume)
+	}
+}
+
+func (f *fakeCloudAPI) updateVolume(w http.ResponseWriter, r *http.Request, args []string) {
+	volume := f.volume(w, args[0])
+	if volume == nil {
+		return
+	}
+	// CloudAPI only updates the name of a volume.
+	var input struct {
+		Name string `json:"name"`
+	}
+	if !fakeDecode(w, r, &input) {
+		return
+	}
+	if input.Name != "" {
+		volume.Name = input.Name
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *fakeCloudAPI) deleteVolume(w http.ResponseWriter, r *http.Request, args []string) {
+	volume := f.volume(w, args[0])
+	if volume == nil {
+		return
+	}
+	if len(volume.Refs) > 0 {
+		fakeError(w, http.StatusConflict, "VolumeInUse", fmt.Sprintf("volume %s is in use", volume.ID))
+		return
+	}
+	delete(f.volumes, volume.ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// fakeVolumeSizes are the sizes, in MiB, tritonnfs volumes can be created
+// with.
+var fakeVolumeSizes = []int64{10240, 20480, 30720, 40960, 51200, 102400}
+
+func (f *fakeCloudAPI) listVolumeSizes(w http.ResponseWriter, r *http.Request, _ []string) {
+	volumeType := r.URL.Query().Get("type")
+	if volumeType != "" && volumeType != "tritonnfs" {
+		fakeError(w, http.StatusConflict, "InvalidArgument", fmt.Sprintf("unsupported volume type %q", volumeType))
+		return
+	}
+	type volumeSize struct {
+		Size int64  `json:"size"`
+		Type string `json:"type"`
+	}
+	// The order of the sizes is not documented, so list the largest first.
+	result := []volumeSize{}
+	for i := len(fakeVolumeSizes) - 1; i >= 0; i-- {
+		result = append(result, volumeSize{Size: fakeVolumeSizes[i], Type: "tritonnfs"})
+	}
+	fakeJSON(w, http.StatusOK, result)
+}
+
+// Networks and fabrics
+
+func (f *fakeCloudAPI) sortedNetworks(include func(*fakeNetwork) bool) []*fakeNetwork {
+	result := []*fakeNetwork{}
+	for _, n := range f.networks {
+		if include(n) {
+			result = append(result, n)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+func (f *fakeCloudAPI) listNetworks(w http.ResponseWriter, r *http.Request, _ []string) {
+	fakeJSON(w, http.StatusOK, f.sortedNetworks(func(*fakeNetwork) bool { return true }))
+}
+
+func (f *fakeCloudAPI) getNetwork(w http.ResponseWriter, r *http.Request, args []string) {
+	n, found := f.networks[args[0]]
+	if !found {
+		fakeError(w, http.StatusNotFound, "ResourceNotFound", fmt.Sprintf("network %s not found", args[0]))
+		return
+	}
+	fakeJSON(w, http.StatusOK, n)
+}
+
+func (f *fakeCloudAPI) networkIP(n *fakeNetwork, ip string) map[string]interface{} {
+	result := map[string]interface{}{
+		"ip":       ip,
+		"reserved": n.reserved[ip],
+		"managed":  ip == n.Gateway,
+	}
+	if owner, used := n.used[ip]; used {
+		result["owner_uuid"] = fakeAccountID
+		result["belongs_to_uuid"] = owner
+		result["belongs_to_type"] = "zone"
+	} else if n.reserved[ip] {
+		result["owner_uuid"] = fakeAccountID
+	}
+	return result
+}
+
+func (f *fakeCloudAPI) listNetworkIPs(w http.ResponseWriter, r *http.Request, args []string) {
+	n, found := f.networks[args[0]]
+	if !found {
+		fakeError(w, http.StatusNotFound, "ResourceNotFound", fmt.Sprintf("network %s not found", args[0]))
+		return
+	}
+	ips := map[string]bool{}
+	if n.Gateway != "" {
+		ips[n.Gateway] = true
+	}
+	for ip := range n.used {
+		ips[ip] = true
+	}
+	for ip, reserved := range n.reserved {
+		ips[ip] = ips[ip] || reserved
+	}
+	sorted := []string{}
+	for ip := range ips {
+		sorted = append(sorted, ip)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return fakeIPToUint(net.ParseIP(sorted[i])) < fakeIPToUint(net.ParseIP(sorted[j]))
+	})
+	result := []map[string]interface{}{}
+	for _, ip := range sorted {
+		result = append(result, f.networkIP(n, ip))
+	}
+	fakeJSON(w, http.StatusOK, result)
+}
+
+func (f *fakeCloudAPI) findNetworkIP(w http.ResponseWriter, args []string) *fakeNetwork {
+	n, found := f.networks[args[0]]
+	if !found {
+		fakeError(w, http.StatusNotFound, "ResourceNotFound", fmt.Sprintf("network %s not found", args[0]))
+		return nil
+	}
+	if !fakeSubnetContains(n.Subnet, args[1]) {
+		fakeError(w, http.StatusNotFound, "ResourceNotFound", fmt.Sprintf("IP %s is not in subnet %s of network %s", args[1], n.Subnet, n.Id))
+		return nil
+	}
+	return n
+}
+
+func (f *fakeCloudAPI) getNetworkIP(w http.ResponseWriter, r *http.Request, args []string) {
+	if n := f.findNetworkIP(w, args); n != nil {
+		fakeJSON(w, http.StatusOK, f.networkIP(n, args[1]))
+	}
+}
+
+func (f *fakeCloudAPI) updateNetworkIP(w http.ResponseWriter, r *http.Request, args []string) {
+	n := f.findNetworkIP(w, args)
+	if n == nil {
+		return
+	}
+	var input struct {
+		Reserved bool `json:"reserved"`
+	}
+	if !fakeDecode(w, r, &input) {
+		return
+	}
+	if n.reserved == nil {
+		n.reserved = map[string]bool{}
+	}
+	n.reserved[args[1]] = input.Reserved
+	fakeJSON(w, http.StatusOK, f.networkIP(n, args[1]))
+}
+
+func (f *fakeCloudAPI) vlan(w http.ResponseWriter, id string) *network.FabricVLAN {
+	vlanID, err := strconv.Atoi(id)
+	if err != nil {
+		fakeError(w, http.StatusConflict, "InvalidArgument", fmt.Sprintf("invalid VLAN ID %q", id))
+		return nil
+	}
+	vlan, found := f.vlans[vlanID]
+	if !found {
+		fakeError(w, http.StatusNotFound, "ResourceNotFound", fmt.Sprintf("VLAN %d not found", vlanID))
+		return nil
+	}
+	return vlan
+}
+
+func (f *fakeCloudAPI) listVLANs(w http.ResponseWriter, r *http.Request, _ []string) {
+	result := []*network.FabricVLAN{}
+	for _, vlan := range f.vlans {
+		result = append(result, vlan)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	fakeJSON(w, http.StatusOK, result)
+}
+
+func (f *fakeCloudAPI) createVLAN(w http.ResponseWriter, r *http.Request, _ []string) {
+	var input network.FabricVLAN
+	if !fakeDecode(w, r, &input) {
+		return
+	}
+	if input.ID < 0 || input.ID > 4095 {
+		fakeError(w, http.StatusConflict, "InvalidArgument", "vlan_id must be between 0 and 4095")
+		return
+	}
+	if _, found := f.vlans[input.ID]; found {
+		fakeError(w, http.StatusConflict, "InvalidArgument", fmt.Sprintf("VLAN %d already exists", input.ID))
+		return
+	}
+	f.vlans[input.ID] = &input
+	fakeJSON(w, http.StatusCreated, &input)
+}
+
+func (f *fakeCloudAPI) getVLAN(w http.ResponseWriter, r *http.Request, args []string) {
+	if vlan := f.vlan(w, args[0]); vlan != nil {
+		fakeJSON(w, http.StatusOK, vlan)
+	}
+}
+
+func (f *fakeCloudAPI) updateVLAN(w http.ResponseWriter, r *http.Request, args []string) {
+	vlan := f.vlan(w, args[0])
+	if vlan == nil {
+		return
+	}
+	var input network.FabricVLAN
+	if !fakeDecode(w, r, &input) {
+		return
+	}
+	vlan.Name = input.Name
+	vlan.Description = input.Description
+	fakeJSON(w, http.StatusAccepted, vlan)
+}
+
+func (f *fakeCloudAPI) deleteVLAN(w http.ResponseWriter, r *http.Request, args []string) {
+	vlan := f.vlan(w, args[0])
+	if vlan == nil {
+		return
+	}
+	for _, n := range f.networks {
+		if n.vlanID == vlan.ID {
+			fakeError(w, http.StatusConflict, "InUseError", fmt.Sprintf("VLAN %d has networks", vlan.ID))
+			return
+		}
+	}
+	delete(f.vlans, vlan.ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *fakeCloudAPI) listFabrics(w http.ResponseWriter, r *http.Request, args []string) {
+	vlan := f.vlan(w, args[0])
+	if vlan == nil {
+		return
+	}
+	fakeJSON(w, http.StatusOK, f.sortedNetworks(func(n *fakeNetwork) bool {
+		return n.vlanID == vlan.ID
+	}))
+}
+
+func (f *fakeCloudAPI) createFabric(w http.ResponseWriter, r *http.Request, args []string) {
+	vlan := f.vlan(w, args[0])
+	if vlan == nil {
+		return
+	}
+	var input network.CreateFabricInput
+	if !fakeDecode(w, r, &input) {
+		return
+	}
+	if input.Name == "" || input.Subnet == "" || input.ProvisionStartIP == "" || input.ProvisionEndIP == "" {
+		fakeError(w, http.StatusConflict, "MissingParameter", "name, subnet, provision_start_ip and provision_end_ip are required")
+		return
+	}
+	if _, _, err := net.ParseCIDR(input.Subnet); err != nil {
+		fakeError(w, http.StatusConflict, "InvalidArgument", fmt.Sprintf("invalid subnet %q", input.Subnet))
+		return
+	}
+	for _, ip := range []string{input.ProvisionStartIP, input.ProvisionEndIP, input.Gateway} {
+		if ip != "" && !fakeSubnetContains(input.Subnet, ip) {
+			fakeError(w, http.StatusConflict, "InvalidArgument", fmt.Sprintf("IP %s is not in subnet %s", ip, input.Subnet))
+			return
+		}
+	}
+	for _, n := range f.networks {
+		if n.vlanID == vlan.ID && n.Name == input.Name {
+			fakeError(w, http.StatusConflict, "InvalidArgument", fmt.Sprintf("network %q already exists", input.Name))
+			return
+		}
+	}
+
+	if input.Resolvers == nil {
+		input.Resolvers = []string{}
+	}
+	if input.Routes == nil {
+		input.Routes = map[string]string{}
+	}
+	n := &fakeNetwork{
+		Network: network.Network{
+			Id:                  f.newUUID(),
+			Name:                input.Name,
+			Fabric:              true,
+			Description:         input.Description,
+			Subnet:              input.Subnet,
+			ProvisioningStartIP: input.ProvisionStartIP,
+			ProvisioningEndIP:   input.ProvisionEndIP,
+			Gateway:             input.Gateway,
+			Resolvers:           input.Resolvers,
+			Routes:              input.Routes,
+			InternetNAT:         input.InternetNAT,
+		},
+		vlanID: vlan.ID,
+		used:   map[string]string{},
+	}
+	f.networks[n.Id] = n
+	fakeJSON(w, http.StatusCreated, n)
+}
+
+func (f *fakeCloudAPI) fabric(w http.ResponseWriter, vlanID, id string) *fakeNetwork {
+	vlan := f.vlan(w, vlanID)
+	if vlan == nil {
+		return nil
+	}
+	n, found := f.networks[id]
+	if !found || n.vlanID != vlan.ID {
+		fakeError(w, http.StatusNotFound, "ResourceNotFound", fmt.Sprintf("network %s not found on VLAN %d", id, vlan.ID))
+		return nil
+	}
+	return n
+}
+
+func (f *fakeCloudAPI) getFabric(w http.ResponseWriter, r *http.Request, args []string) {
+	if n := f.fabric(w, args[0], args[1]); n != nil {
+		fakeJSON(w, http.StatusOK, n)
+	}
+}
+
+func (f *fakeCloudAPI) updateFabric(w http.ResponseWriter, r *http.Request, args []string) {
+	n := f.fabric(w, args[0], args[1])
+	if n == nil {
+		return
+	}
+	var input struct {
+		Name             *string           `json:"name"`
+		Description      *string           `json:"description"`
+		ProvisionStartIP *string           `json:"provision_start_ip"`
+		ProvisionEndIP   *string           `json:"provision_end_ip"`
+		Resolvers        []string          `json:"resolvers"`
+		Routes           map[string]string `json:"routes"`
+		Subnet           *string           `json:"subnet"`
+		Gateway          *string           `json:"gateway"`
+		InternetNAT      *bool             `json:"internet_nat"`
+	}
+	if !fakeDecode(w, r, &input) {
+		return
+	}
+	if input.Subnet != nil || input.Gateway != nil || input.InternetNAT != nil {
+		fakeError(w, http.StatusConflict, "InvalidArgument", "subnet, gateway and internet_nat cannot be changed")
+		return
+	}
+	for _, ip := range []*string{input.ProvisionStartIP, input.ProvisionEndIP} {
+		if ip != nil && !fakeSubnetContains(n.Subnet, *ip) {
+			fakeError(w, http.StatusConflict, "InvalidArgument", fmt.Sprintf("IP %s is not in subnet %s", *ip, n.Subnet))
+			return
+		}
+	}
+
+	if input.Name != nil {
+		n.Name = *input.Name
+	}
+	if input.Description != nil {
+		n.Description = *input.Description
+	}
+	if input.ProvisionStartIP != nil {
+		n.ProvisioningStartIP = *input.ProvisionStartIP
+	}
+	if input.ProvisionEndIP != nil {
+		n.ProvisioningEndIP = *input.ProvisionEndIP
+	}
+	if input.Resolvers != nil {
+		n.Resolvers = input.Resolvers
+	}
+	if input.Routes != nil {
+		n.Routes = input.Routes
+	}
+	fakeJSON(w, http.StatusAccepted, n)
+}
+
+func (f *fakeCloudAPI) deleteFabric(w http.ResponseWriter, r *http.Request, args []string) {
+	n := f.fabric(w, args[0], args[1])
+	if n == nil {
+		return
+	}
+	if len(n.used) > 0 {
+		fakeError(w, http.StatusConflict, "InUseError", fmt.Sprintf("network %s is in use", n.Id))
+		return
+	}
+	delete(f.networks, n.Id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Firewall rules
+
+func (f *fakeCloudAPI) sortedRules(include func(*firewallRule) bool) []*firewallRule {
+	result := []*firewallRule{}
+	for _, rule := range f.rules {
+		if include(rule) {
+			result = append(result, rule)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+func (f *fakeCloudAPI) rule(w http.ResponseWriter, id string) *firewallRule {
+	rule, found := f.rules[id]
+	if !found {
+		fakeError(w, http.StatusNotFound, "ResourceNotFound", fmt.Sprintf("rule %s not found", id))
+		return nil
+	}
+	return rule
+}
+
+func (f *fakeCloudAPI) listRules(w http.ResponseWriter, r *http.Request, _ []string) {
+	fakeJSON(w, http.StatusOK, f.sortedRules(func(*firewallRule) bool { return true }))
+}
+
+// fakeRuleInput is the body of a create or update rule request. Fields
+// which are not sent are left unchanged by an update.
+type fakeRuleInput struct {
+	Rule        string `json:"rule"`
+	Enabled     *bool  `json:"enabled"`
+	Description string `json:"description"`
+	Log         *bool  `json:"log"`
+}
+
+func (f *fakeCloudAPI) createRule(w http.ResponseWriter, r *http.Request, _ []string) {
+	var input fakeRuleInput
+	if !fakeDecode(w, r, &input) {
+		return
+	}
+	if !fakeRuleSyntax.MatchString(strings.TrimSpace(input.Rule)) {
+		fakeError(w, http.StatusConflict, "InvalidParameters", fmt.Sprintf("invalid rule %q", input.Rule))
+		return
+	}
+	rule := &firewallRule{
+		FirewallRule: network.FirewallRule{
+			ID:          f.newUUID(),
+			Enabled:     input.Enabled != nil && *input.Enabled,
+			Rule:        strings.TrimSpace(input.Rule),
+			Description: input.Description,
+		},
+		Log: input.Log != nil && *input.Log,
+	}
+	f.rules[rule.ID] = rule
+	fakeJSON(w, http.StatusCreated, rule)
+}
+
+func (f *fakeCloudAPI) getRule(w http.ResponseWriter, r *http.Request, args []string) {
+	if rule := f.rule(w, args[0]); rule != nil {
+		fakeJSON(w, http.StatusOK, rule)
+	}
+}
+
+func (f *fakeCloudAPI) updateRule(w http.ResponseWriter, r *http.Request, args []string) {
+	rule := f.rule(w, args[0])
+	if rule == nil {
+		return
+	}
+	if rule.Global {
+		fakeError(w, http.StatusForbidden, "NotAuthorized", "global rules cannot be modified")
+		return
+	}
+	var input fakeRuleInput
+	if !fakeDecode(w, r, &input) {
+		return
+	}
+	if !fakeRuleSyntax.MatchString(strings.TrimSpace(input.Rule)) {
+		fakeError(w, http.StatusConflict, "InvalidParameters", fmt.Sprintf("invalid rule %q", input.Rule))
+		return
+	}
+	rule.Rule = strings.TrimSpace(input.Rule)
+	rule.Description = input.Description
+	if input.Enabled != nil {
+		rule.Enabled = *input.Enabled
+	}
+	if input.Log != nil {
+		rule.Log = *input.Log
+	}
+	fakeJSON(w, http.StatusOK, rule)
+}
+
+func (f *fakeCloudAPI) setRuleEnabled(w http.ResponseWriter, id string, enabled bool) {
+	rule := f.rule(w, id)
+	if rule == nil {
+		return
+	}
+	if rule.Global {
+		fakeError(w, http.StatusForbidden, "NotAuthorized", "global rules cannot be modified")
+		return
+	}
+	rule.Enabled = enabled
+	fakeJSON(w, http.StatusOK, rule)
+}
+
+func (f *fakeCloudAPI) enableRule(w http.ResponseWriter, r *http.Request, args []string) {
+	f.setRuleEnabled(w, args[0], true)
+}
+
+func (f *fakeCloudAPI) disableRule(w http.ResponseWriter, r *http.Request, args []string) {
+	f.setRuleEnabled(w, args[0], false)
+}
+
+func (f *fakeCloudAPI) deleteRule(w http.ResponseWriter, r *http.Request, args []string) {
+	rule := f.rule(w, args[0])
+	if rule == nil {
+		return
+	}
+	if rule.Global {
+		fakeError(w, http.StatusForbidden, "NotAuthorized", "global rules cannot be deleted")
+		return
+	}
+	delete(f.rules, rule.ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *fakeCloudAPI) listMachineRules(w http.ResponseWriter, r *http.Request, args []string) {
+	m := f.machine(w, args[0])
+	if m == nil {
+		return
+	}
+	fakeJSON(w, http.StatusOK, f.sortedRules(func(rule *firewallRule) bool {
+		return fakeRuleAffects(rule, m)
+	}))
+}
+
+func (f *fakeCloudAPI) listRuleMachines(w http.ResponseWriter, r *http.Request, args []string) {
+	rule := f.rule(w, args[0])
+	if rule == nil {
+		return
+	}
+	result := []*fakeMachine{}
+	for _, m := range f.machines {
+		if m.State != machineStateDeleted && fakeRuleAffects(rule, m) {
+			result = append(result, m)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Created.Before(result[j].Created) })
+	fakeJSON(w, http.StatusOK, result)
+}
+
+// fakeRuleSyntax is a coarse check of the FWRULE grammar: a FROM and a TO
+// target list followed by an action and a protocol.
+var fakeRuleSyntax = regexp.MustCompile(`(?i)^from\s+.+\s+to\s+.+\s+(allow|block)\s+(tcp|udp|icmp|icmp6|ah|esp)\b.*$`)
+
+var fakeRuleTagRegexp = regexp.MustCompile(`(?i)\btag\s+"?([^"\s()]+)"?(?:\s*=\s*"?([^"\s()]+)"?)?`)
+
+// fakeRuleAffects reports whether a rule applies to the given machine, either
+// because it targets all VMs, the machine itself or one of its tags.
+func fakeRuleAffects(rule *firewallRule, m *fakeMachine) bool {
+	text := strings.ToLower(rule.Rule)
+	if strings.Contains(text, "all vms") || strings.Contains(text, "vm "+m.ID) {
+		return true
+	}
+	for _, match := range fakeRuleTagRegexp.FindAllStringSubmatch(rule.Rule, -1) {
+		value, found := m.Tags[match[1]]
+		if found && (match[2] == "" || fmt.Sprint(value) == match[2]) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestFakeCloudAPI_signature(t *testing.T) {
+	f := newFakeCloudAPI(t)
+
+	a, err := f.Client(t).Account()
+	if err != nil {
+		t.Fatal(err)
+	}
+	acct, err := a.Get(context.Background(), &account.GetInput{})
+	if err != nil {
+		t.Fatalf("signed request was rejected: %s", err)
+	}
+	if acct.Login != f.Account {
+		t.Fatalf("expected login %q, got %q", f.Account, acct.Login)
+	}
+
+	cases := []struct {
+		name          string
+		authorization string
+	}{
+		{"missing", ""},
+		{"unknown key", fmt.Sprintf(`Signature keyId="/%s/keys/00:11:22",algorithm="rsa-sha1",headers="date",signature="AAAA"`, f.Account)},
+		{"wrong account", fmt.Sprintf(`Signature keyId="/other/keys/%s",algorithm="rsa-sha1",headers="date",signature="AAAA"`, f.KeyID)},
+		{"bad signature", fmt.Sprintf(`Signature keyId="/%s/keys/%s",algorithm="rsa-sha1",headers="date",signature="AAAA"`, f.Account, f.KeyID)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, f.URL()+"/"+f.Account, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Date", time.Now().UTC().Format(time.RFC1123))
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != http.StatusUnauthorized {
+				t.Fatalf("expected status %d, got %d", http.StatusUnauthorized, resp.StatusCode)
+			}
+		})
+	}
+}
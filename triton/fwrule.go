@@ -0,0 +1,748 @@
+package triton
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// This file holds a parser and renderer for the subset of the CloudAPI
+// firewall rule language (FWRULE) which the provider needs to understand:
+//
+//	FROM <targets> TO <targets> (ALLOW | BLOCK) <protocol> [<ports or types>] [PRIORITY <n>]
+//
+// See https://docs.tritondatacenter.com/public-cloud/network/firewall/cloud-firewall-rules-reference
+// for the full reference.
+
+const (
+	fwruleTargetAny    = "any"
+	fwruleTargetAllVMs = "all vms"
+	fwruleTargetVM     = "vm"
+	fwruleTargetIP     = "ip"
+	fwruleTargetSubnet = "subnet"
+	fwruleTargetTag    = "tag"
+
+	fwruleActionAllow = "allow"
+	fwruleActionBlock = "block"
+
+	fwrulePortsAll = "all"
+)
+
+// fwruleProtocols are the protocols a rule can apply to.
+var fwruleProtocols = []string{"tcp", "udp", "icmp", "icmp6", "ah", "esp"}
+
+// fwruleTarget is a single FROM or TO target of a firewall rule.
+type fwruleTarget struct {
+	// Kind is one of the fwruleTarget constants.
+	Kind string
+	// Value is the VM UUID, IP address, subnet or tag name.
+	Value string
+	// TagValue is the value a tag must have, if HasTagValue is set.
+	TagValue    string
+	HasTagValue bool
+}
+
+// fwrule is a parsed firewall rule.
+type fwrule struct {
+	From     []fwruleTarget
+	To       []fwruleTarget
+	Action   string
+	Protocol string
+	// Ports holds the ports of a TCP or UDP rule: `all`, a port number or a
+	// range of the form `start-end`.
+	Ports []string
+	// Types holds the types of an ICMP rule: `all`, a type or a type and
+	// code of the form `type:code`.
+	Types    []string
+	Priority int
+}
+
+// parseFWRule parses the text of a firewall rule. Keywords are not case
+// sensitive, and the optional spellings CloudAPI accepts, such as port ranges
+// with or without spaces around the dash, are normalized.
+func parseFWRule(text string) (*fwrule, error) {
+	tokens, err := tokenizeFWRule(text)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &fwruleParser{tokens: tokens}
+	rule, err := p.parseRule()
+	if err != nil {
+		return nil, fmt.Errorf("invalid firewall rule %q: %s", strings.TrimSpace(text), err)
+	}
+	if err := rule.validate(); err != nil {
+		return nil, fmt.Errorf("invalid firewall rule %q: %s", strings.TrimSpace(text), err)
+	}
+
+	return rule, nil
+}
+
+// normalizeFWRule returns the text of a rule with its keywords, quoting,
+// spacing and the order of its targets, ports and types made canonical.
+func normalizeFWRule(text string) (string, error) {
+	rule, err := parseFWRule(text)
+	if err != nil {
+		return "", err
+	}
+	rule.normalize()
+	return rule.String(), nil
+}
+
+// suppressEquivalentFWRule is a DiffSuppressFunc for FWRULE text which
+// ignores the differences between a rule and the form CloudAPI stores it in.
+// Rules which do not parse are compared as they are.
+func suppressEquivalentFWRule(k, old, new string, d *schema.ResourceData) bool {
+	if old == "" || new == "" {
+		return false
+	}
+	o, err := normalizeFWRule(old)
+	if err != nil {
+		return strings.TrimSpace(old) == strings.TrimSpace(new)
+	}
+	n, err := normalizeFWRule(new)
+	if err != nil {
+		return strings.TrimSpace(old) == strings.TrimSpace(new)
+	}
+	return o == n
+}
+
+// validate checks that a rule is complete and that its targets, ports and
+// types are well formed.
+func (r *fwrule) validate() error {
+	for _, side := range []struct {
+		name    string
+		targets []fwruleTarget
+	}{{"FROM", r.From}, {"TO", r.To}} {
+		if len(side.targets) == 0 {
+			return fmt.Errorf("%s needs at least one target", side.name)
+		}
+		for _, target := range side.targets {
+			if err := target.validate(); err != nil {
+				return err
+			}
+			if (target.Kind == fwruleTargetAny || target.Kind == fwruleTargetAllVMs) && len(side.targets) > 1 {
+				return fmt.Errorf("%s target %q cannot be combined with other targets", side.name, target.Kind)
+			}
+		}
+	}
+
+	if r.Action != fwruleActionAllow && r.Action != fwruleActionBlock {
+		return fmt.Errorf("action must be ALLOW or BLOCK, got %q", r.Action)
+	}
+
+	switch r.Protocol {
+	case "tcp", "udp":
+		if len(r.Ports) == 0 {
+			return fmt.Errorf("%s rules need at least one port", r.Protocol)
+		}
+		if len(r.Types) > 0 {
+			return fmt.Errorf("%s rules cannot have ICMP types", r.Protocol)
+		}
+		for _, port := range r.Ports {
+			if port == fwrulePortsAll && len(r.Ports) > 1 {
+				return fmt.Errorf("port %q cannot be combined with other ports", port)
+			}
+			if err := validateFWRulePort(port); err != nil {
+				return err
+			}
+		}
+	case "icmp", "icmp6":
+		if len(r.Types) == 0 {
+			return fmt.Errorf("%s rules need at least one type", r.Protocol)
+		}
+		if len(r.Ports) > 0 {
+			return fmt.Errorf("%s rules cannot have ports", r.Protocol)
+		}
+		for _, icmpType := range r.Types {
+			if icmpType == fwrulePortsAll && len(r.Types) > 1 {
+				return fmt.Errorf("type %q cannot be combined with other types", icmpType)
+			}
+			if err := validateFWRuleICMPType(icmpType); err != nil {
+				return err
+			}
+		}
+	case "ah", "esp":
+		if len(r.Ports) > 0 || len(r.Types) > 0 {
+			return fmt.Errorf("%s rules cannot have ports or types", r.Protocol)
+		}
+	default:
+		return fmt.Errorf("protocol must be one of %s, got %q", strings.Join(fwruleProtocols, ", "), r.Protocol)
+	}
+
+	if r.Priority < 0 || r.Priority > 100 {
+		return fmt.Errorf("priority must be between 0 and 100, got %d", r.Priority)
+	}
+
+	return nil
+}
+
+func (t fwruleTarget) validate() error {
+	switch t.Kind {
+	case fwruleTargetAny, fwruleTargetAllVMs:
+	case fwruleTargetVM:
+		if t.Value == "" {
+			return fmt.Errorf("vm target needs a machine UUID")
+		}
+	case fwruleTargetIP:
+		if net.ParseIP(t.Value) == nil {
+			return fmt.Errorf("ip target %q is not an IP address", t.Value)
+		}
+	case fwruleTargetSubnet:
+		ip, subnet, err := net.ParseCIDR(t.Value)
+		if err != nil {
+			return fmt.Errorf("subnet target %q is not a CIDR block", t.Value)
+		}
+		if !ip.Equal(subnet.IP) {
+			return fmt.Errorf("subnet target %q has host bits set, use %q", t.Value, subnet.String())
+		}
+	case fwruleTargetTag:
+		if t.Value == "" {
+			return fmt.Errorf("tag target needs a tag name")
+		}
+	default:
+		return fmt.Errorf("unknown target %q", t.Kind)
+	}
+	return nil
+}
+
+// validateFWRulePort checks a port, `all` or a range of ports.
+func validateFWRulePort(port string) error {
+	if port == fwrulePortsAll {
+		return nil
+	}
+	start, end, isRange := strings.Cut(port, "-")
+	first, err := parseFWRuleNumber(start, 1, 65535)
+	if err != nil {
+		return fmt.Errorf("invalid port %q: %s", port, err)
+	}
+	if isRange {
+		last, err := parseFWRuleNumber(end, 1, 65535)
+		if err != nil {
+			return fmt.Errorf("invalid port range %q: %s", port, err)
+		}
+		if first >= last {
+			return fmt.Errorf("invalid port range %q: start must be lower than end", port)
+		}
+	}
+	return nil
+}
+
+// validateFWRuleICMPType checks an ICMP type, `all` or a type and code.
+func validateFWRuleICMPType(icmpType string) error {
+	if icmpType == fwrulePortsAll {
+		return nil
+	}
+	typ, code, hasCode := strings.Cut(icmpType, ":")
+	if _, err := parseFWRuleNumber(typ, 0, 255); err != nil {
+		return fmt.Errorf("invalid ICMP type %q: %s", icmpType, err)
+	}
+	if hasCode {
+		if _, err := parseFWRuleNumber(code, 0, 255); err != nil {
+			return fmt.Errorf("invalid ICMP code %q: %s", icmpType, err)
+		}
+	}
+	return nil
+}
+
+func parseFWRuleNumber(s string, min, max int) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", s)
+	}
+	if n < min || n > max {
+		return 0, fmt.Errorf("%d is not between %d and %d", n, min, max)
+	}
+	return n, nil
+}
+
+// String renders a rule in FWRULE syntax.
+func (r *fwrule) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "FROM %s TO %s %s %s",
+		renderFWRuleTargets(r.From), renderFWRuleTargets(r.To), strings.ToUpper(r.Action), r.Protocol)
+
+	var parts []string
+	for _, port := range r.Ports {
+		if start, end, isRange := strings.Cut(port, "-"); isRange {
+			parts = append(parts, fmt.Sprintf("PORTS %s - %s", start, end))
+		} else {
+			parts = append(parts, "PORT "+port)
+		}
+	}
+	for _, icmpType := range r.Types {
+		if typ, code, hasCode := strings.Cut(icmpType, ":"); hasCode {
+			parts = append(parts, fmt.Sprintf("TYPE %s CODE %s", typ, code))
+		} else {
+			parts = append(parts, "TYPE "+icmpType)
+		}
+	}
+	switch len(parts) {
+	case 0:
+	case 1:
+		b.WriteString(" " + parts[0])
+	default:
+		b.WriteString(" (" + strings.Join(parts, " AND ") + ")")
+	}
+
+	if r.Priority > 0 {
+		fmt.Fprintf(&b, " PRIORITY %d", r.Priority)
+	}
+
+	return b.String()
+}
+
+// normalize puts the machine UUIDs, addresses and numbers of a rule in their
+// canonical form and sorts its targets, ports and types, none of whose order
+// matters, so that equivalent rules render the same way.
+func (r *fwrule) normalize() {
+	for _, targets := range [][]fwruleTarget{r.From, r.To} {
+		for i := range targets {
+			targets[i].normalize()
+		}
+		sort.SliceStable(targets, func(i, j int) bool { return targets[i].String() < targets[j].String() })
+	}
+	for i, port := range r.Ports {
+		r.Ports[i] = normalizeFWRulePortRange(normalizeFWRuleNumbers(port, "-"))
+	}
+	for i, icmpType := range r.Types {
+		r.Types[i] = normalizeFWRuleNumbers(icmpType, ":")
+	}
+	sortFWRuleNumbers(r.Ports, "-")
+	sortFWRuleNumbers(r.Types, ":")
+}
+
+// normalize lowercases a machine UUID and renders an IP address or subnet
+// the way CloudAPI stores it.
+func (t *fwruleTarget) normalize() {
+	switch t.Kind {
+	case fwruleTargetVM:
+		t.Value = strings.ToLower(t.Value)
+	case fwruleTargetIP:
+		if ip := net.ParseIP(t.Value); ip != nil {
+			t.Value = ip.String()
+		}
+	case fwruleTargetSubnet:
+		if _, subnet, err := net.ParseCIDR(t.Value); err == nil {
+			t.Value = subnet.String()
+		}
+	}
+}
+
+// normalizeFWRuleNumbers drops the leading zeros of a port, port range, type
+// or type and code.
+func normalizeFWRuleNumbers(value, sep string) string {
+	parts := strings.SplitN(value, sep, 2)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return value
+		}
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, sep)
+}
+
+// sortFWRuleNumbers sorts ports or types by their leading number, which
+// `all` sorts before.
+func sortFWRuleNumbers(values []string, sep string) {
+	key := func(v string) (int, int) {
+		first, second, _ := strings.Cut(v, sep)
+		a, err := strconv.Atoi(first)
+		if err != nil {
+			return -1, -1
+		}
+		b, _ := strconv.Atoi(second)
+		return a, b
+	}
+	sort.SliceStable(values, func(i, j int) bool {
+		ai, bi := key(values[i])
+		aj, bj := key(values[j])
+		if ai != aj {
+			return ai < aj
+		}
+		return bi < bj
+	})
+}
+
+func renderFWRuleTargets(targets []fwruleTarget) string {
+	parts := make([]string, 0, len(targets))
+	for _, target := range targets {
+		parts = append(parts, target.String())
+	}
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	return "(" + strings.Join(parts, " OR ") + ")"
+}
+
+// String renders a target in FWRULE syntax.
+func (t fwruleTarget) String() string {
+	switch t.Kind {
+	case fwruleTargetAny, fwruleTargetAllVMs:
+		return t.Kind
+	case fwruleTargetTag:
+		if t.HasTagValue {
+			return fmt.Sprintf("tag %s = %s", strconv.Quote(t.Value), strconv.Quote(t.TagValue))
+		}
+		return "tag " + strconv.Quote(t.Value)
+	default:
+		return t.Kind + " " + t.Value
+	}
+}
+
+// fwruleToken is a word, a quoted string or one of the punctuation
+// characters `(`, `)`, `,` and `=`.
+type fwruleToken struct {
+	text   string
+	quoted bool
+}
+
+func tokenizeFWRule(text string) ([]fwruleToken, error) {
+	var tokens []fwruleToken
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')' || c == ',' || c == '=':
+			tokens = append(tokens, fwruleToken{text: string(c)})
+			i++
+		case c == '"':
+			// A backslash escapes the character after it, as in the
+			// strings rules are rendered with.
+			var value strings.Builder
+			for i++; i < len(text) && text[i] != '"'; i++ {
+				if text[i] == '\\' && i+1 < len(text) {
+					i++
+				}
+				value.WriteByte(text[i])
+			}
+			if i >= len(text) {
+				return nil, fmt.Errorf("invalid firewall rule %q: unterminated string", strings.TrimSpace(text))
+			}
+			tokens = append(tokens, fwruleToken{text: value.String(), quoted: true})
+			i++
+		default:
+			start := i
+			for i < len(text) && !strings.ContainsRune(" \t\n\r(),=\"", rune(text[i])) {
+				i++
+			}
+			tokens = append(tokens, fwruleToken{text: text[start:i]})
+		}
+	}
+	return tokens, nil
+}
+
+type fwruleParser struct {
+	tokens []fwruleToken
+	pos    int
+}
+
+func (p *fwruleParser) peek() (fwruleToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return fwruleToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+// peekKeyword reports whether the next token is the given unquoted keyword.
+func (p *fwruleParser) peekKeyword(keyword string) bool {
+	token, ok := p.peek()
+	return ok && !token.quoted && strings.EqualFold(token.text, keyword)
+}
+
+func (p *fwruleParser) next(what string) (fwruleToken, error) {
+	token, ok := p.peek()
+	if !ok {
+		return fwruleToken{}, fmt.Errorf("expected %s, got end of rule", what)
+	}
+	p.pos++
+	return token, nil
+}
+
+func (p *fwruleParser) expect(keyword string) error {
+	if !p.peekKeyword(keyword) {
+		if token, ok := p.peek(); ok {
+			return fmt.Errorf("expected %s, got %q", keyword, token.text)
+		}
+		return fmt.Errorf("expected %s, got end of rule", keyword)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *fwruleParser) parseRule() (*fwrule, error) {
+	rule := &fwrule{}
+	var err error
+
+	if err := p.expect("FROM"); err != nil {
+		return nil, err
+	}
+	if rule.From, err = p.parseTargets(); err != nil {
+		return nil, err
+	}
+	if err := p.expect("TO"); err != nil {
+		return nil, err
+	}
+	if rule.To, err = p.parseTargets(); err != nil {
+		return nil, err
+	}
+
+	action, err := p.next("ALLOW or BLOCK")
+	if err != nil {
+		return nil, err
+	}
+	rule.Action = strings.ToLower(action.text)
+
+	protocol, err := p.next("a protocol")
+	if err != nil {
+		return nil, err
+	}
+	rule.Protocol = strings.ToLower(protocol.text)
+
+	switch rule.Protocol {
+	case "tcp", "udp":
+		if rule.Ports, err = p.parseProtocolTargets("PORT", p.parsePorts); err != nil {
+			return nil, err
+		}
+	case "icmp", "icmp6":
+		if rule.Types, err = p.parseProtocolTargets("TYPE", p.parseType); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.peekKeyword("PRIORITY") {
+		p.pos++
+		token, err := p.next("a priority")
+		if err != nil {
+			return nil, err
+		}
+		if rule.Priority, err = parseFWRuleNumber(token.text, 0, 100); err != nil {
+			return nil, fmt.Errorf("invalid priority: %s", err)
+		}
+	}
+
+	if token, ok := p.peek(); ok {
+		return nil, fmt.Errorf("unexpected %q after the end of the rule", token.text)
+	}
+
+	return rule, nil
+}
+
+// parseTargets parses a single target or a parenthesized list of targets
+// separated by OR.
+func (p *fwruleParser) parseTargets() ([]fwruleTarget, error) {
+	if token, ok := p.peek(); !ok || token.quoted || token.text != "(" {
+		return p.parseTarget()
+	}
+	p.pos++
+
+	var targets []fwruleTarget
+	for {
+		parsed, err := p.parseTarget()
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, parsed...)
+
+		token, err := p.next(`OR or ")"`)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case token.text == ")" && !token.quoted:
+			return targets, nil
+		case strings.EqualFold(token.text, "OR") && !token.quoted:
+		default:
+			return nil, fmt.Errorf(`expected OR or ")", got %q`, token.text)
+		}
+	}
+}
+
+// parseTarget parses a single target. A tag with a list of values stands
+// for one target per value.
+func (p *fwruleParser) parseTarget() ([]fwruleTarget, error) {
+	token, err := p.next("a target")
+	if err != nil {
+		return nil, err
+	}
+	kind := strings.ToLower(token.text)
+	if token.quoted {
+		return nil, fmt.Errorf("expected a target, got %q", token.text)
+	}
+
+	switch kind {
+	case fwruleTargetAny:
+		return []fwruleTarget{{Kind: fwruleTargetAny}}, nil
+	case "all":
+		if !p.peekKeyword("vms") {
+			return nil, fmt.Errorf(`expected "all vms"`)
+		}
+		p.pos++
+		return []fwruleTarget{{Kind: fwruleTargetAllVMs}}, nil
+	case fwruleTargetVM, fwruleTargetIP, fwruleTargetSubnet:
+		value, err := p.next("a " + kind)
+		if err != nil {
+			return nil, err
+		}
+		return []fwruleTarget{{Kind: kind, Value: value.text}}, nil
+	case fwruleTargetTag:
+		name, err := p.next("a tag name")
+		if err != nil {
+			return nil, err
+		}
+		if token, ok := p.peek(); !ok || token.quoted || token.text != "=" {
+			return []fwruleTarget{{Kind: fwruleTargetTag, Value: name.text}}, nil
+		}
+		p.pos++
+
+		var values []string
+		if token, ok := p.peek(); ok && !token.quoted && token.text == "(" {
+			p.pos++
+			for {
+				value, err := p.next("a tag value")
+				if err != nil {
+					return nil, err
+				}
+				values = append(values, value.text)
+				token, err := p.next(`OR or ")"`)
+				if err != nil {
+					return nil, err
+				}
+				if token.text == ")" && !token.quoted {
+					break
+				}
+				if !strings.EqualFold(token.text, "OR") || token.quoted {
+					return nil, fmt.Errorf(`expected OR or ")", got %q`, token.text)
+				}
+			}
+		} else {
+			value, err := p.next("a tag value")
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value.text)
+		}
+
+		targets := make([]fwruleTarget, 0, len(values))
+		for _, value := range values {
+			targets = append(targets, fwruleTarget{Kind: fwruleTargetTag, Value: name.text, TagValue: value, HasTagValue: true})
+		}
+		return targets, nil
+	default:
+		return nil, fmt.Errorf("unknown target %q", token.text)
+	}
+}
+
+// parseProtocolTargets parses the ports or types of a rule: either a single
+// one, or a parenthesized list separated by AND.
+func (p *fwruleParser) parseProtocolTargets(keyword string, parse func() ([]string, error)) ([]string, error) {
+	token, ok := p.peek()
+	if !ok || token.quoted || token.text != "(" {
+		return parse()
+	}
+	p.pos++
+
+	var result []string
+	for {
+		parsed, err := parse()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, parsed...)
+
+		token, err := p.next(`AND or ")"`)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case token.text == ")" && !token.quoted:
+			return result, nil
+		case strings.EqualFold(token.text, "AND") && !token.quoted:
+		default:
+			return nil, fmt.Errorf(`expected AND or ")" between %ss, got %q`, keyword, token.text)
+		}
+	}
+}
+
+// parsePorts parses `PORT <n>`, `PORT all` or `PORTS <list>`, where the
+// list holds ports and ranges separated by commas.
+func (p *fwruleParser) parsePorts() ([]string, error) {
+	token, err := p.next("PORT or PORTS")
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case strings.EqualFold(token.text, "PORT"):
+		port, err := p.next("a port")
+		if err != nil {
+			return nil, err
+		}
+		if strings.EqualFold(port.text, fwrulePortsAll) {
+			return []string{fwrulePortsAll}, nil
+		}
+		return []string{port.text}, nil
+	case strings.EqualFold(token.text, "PORTS"):
+		var ports []string
+		for {
+			port, err := p.next("a port")
+			if err != nil {
+				return nil, err
+			}
+			item := port.text
+			if next, ok := p.peek(); ok && !next.quoted && next.text == "-" {
+				p.pos++
+				end, err := p.next("the end of a port range")
+				if err != nil {
+					return nil, err
+				}
+				item += "-" + end.text
+			}
+			ports = append(ports, normalizeFWRulePortRange(item))
+
+			if next, ok := p.peek(); !ok || next.quoted || next.text != "," {
+				return ports, nil
+			}
+			p.pos++
+		}
+	default:
+		return nil, fmt.Errorf("expected PORT or PORTS, got %q", token.text)
+	}
+}
+
+// normalizeFWRulePortRange turns a range whose start and end are the same
+// into a single port.
+func normalizeFWRulePortRange(port string) string {
+	if start, end, isRange := strings.Cut(port, "-"); isRange && start == end {
+		return start
+	}
+	return port
+}
+
+// parseType parses `TYPE <n> [CODE <n>]` or `TYPE all`.
+func (p *fwruleParser) parseType() ([]string, error) {
+	if err := p.expect("TYPE"); err != nil {
+		return nil, err
+	}
+	typ, err := p.next("an ICMP type")
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(typ.text, fwrulePortsAll) {
+		return []string{fwrulePortsAll}, nil
+	}
+	if !p.peekKeyword("CODE") {
+		return []string{typ.text}, nil
+	}
+	p.pos++
+	code, err := p.next("an ICMP code")
+	if err != nil {
+		return nil, err
+	}
+	return []string{typ.text + ":" + code.text}, nil
+}
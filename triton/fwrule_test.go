@@ -0,0 +1,163 @@
+package triton
+
+import (
+	"testing"
+)
+
+func TestParseFWRule(t *testing.T) {
+	cases := []struct {
+		text     string
+		rendered string
+	}{
+		{
+			text:     `FROM any TO all vms ALLOW tcp PORT 22`,
+			rendered: `FROM any TO all vms ALLOW tcp PORT 22`,
+		},
+		{
+			text:     `from any to tag "www" allow tcp port 80`,
+			rendered: `FROM any TO tag "www" ALLOW tcp PORT 80`,
+		},
+		{
+			text:     `FROM (ip 10.0.0.1 OR subnet 10.1.0.0/16) TO tag role = (web OR api) BLOCK udp (PORT 53 AND PORT 123)`,
+			rendered: `FROM (ip 10.0.0.1 OR subnet 10.1.0.0/16) TO (tag "role" = "web" OR tag "role" = "api") BLOCK udp (PORT 53 AND PORT 123)`,
+		},
+		{
+			text:     `FROM any TO vm 3d51f2d5-46f2-4da5-bb04-3238f2f64768 ALLOW tcp PORTS 8000-8080`,
+			rendered: `FROM any TO vm 3d51f2d5-46f2-4da5-bb04-3238f2f64768 ALLOW tcp PORTS 8000 - 8080`,
+		},
+		{
+			text:     `FROM any TO all vms ALLOW tcp PORTS 22, 8000 - 8080`,
+			rendered: `FROM any TO all vms ALLOW tcp (PORT 22 AND PORTS 8000 - 8080)`,
+		},
+		{
+			text:     `FROM any TO all vms ALLOW icmp TYPE 8 CODE 0`,
+			rendered: `FROM any TO all vms ALLOW icmp TYPE 8 CODE 0`,
+		},
+		{
+			text:     `FROM any TO all vms ALLOW icmp (TYPE 0 AND TYPE 3 CODE 4)`,
+			rendered: `FROM any TO all vms ALLOW icmp (TYPE 0 AND TYPE 3 CODE 4)`,
+		},
+		{
+			text:     `FROM all vms TO any BLOCK tcp PORT all PRIORITY 10`,
+			rendered: `FROM all vms TO any BLOCK tcp PORT all PRIORITY 10`,
+		},
+		{
+			text:     `FROM any TO all vms ALLOW esp`,
+			rendered: `FROM any TO all vms ALLOW esp`,
+		},
+		{
+			text:     `FROM any TO tag "say \"hi\"" = "a\\b" ALLOW tcp PORT 22`,
+			rendered: `FROM any TO tag "say \"hi\"" = "a\\b" ALLOW tcp PORT 22`,
+		},
+	}
+
+	for _, c := range cases {
+		rule, err := parseFWRule(c.text)
+		if err != nil {
+			t.Errorf("error parsing %q: %s", c.text, err)
+			continue
+		}
+		if got := rule.String(); got != c.rendered {
+			t.Errorf("expected %q to render as %q, got %q", c.text, c.rendered, got)
+			continue
+		}
+
+		reparsed, err := parseFWRule(rule.String())
+		if err != nil {
+			t.Errorf("error parsing rendered %q: %s", rule.String(), err)
+			continue
+		}
+		if got := reparsed.String(); got != c.rendered {
+			t.Errorf("expected %q to render as itself, got %q", c.rendered, got)
+		}
+	}
+}
+
+func TestParseFWRule_invalid(t *testing.T) {
+	for _, text := range []string{
+		``,
+		`FROM any ALLOW tcp PORT 22`,
+		`FROM any TO all vms PERMIT tcp PORT 22`,
+		`FROM any TO all vms ALLOW sctp PORT 22`,
+		`FROM any TO all vms ALLOW tcp`,
+		`FROM any TO all vms ALLOW tcp PORT 70000`,
+		`FROM any TO all vms ALLOW tcp PORTS 8080 - 8000`,
+		`FROM any TO all vms ALLOW icmp PORT 22`,
+		`FROM any TO all vms ALLOW icmp TYPE 256`,
+		`FROM any TO all vms ALLOW esp PORT 22`,
+		`FROM (any OR ip 10.0.0.1) TO all vms ALLOW tcp PORT 22`,
+		`FROM ip 10.0.0.300 TO all vms ALLOW tcp PORT 22`,
+		`FROM subnet 10.0.0.1/8 TO all vms ALLOW tcp PORT 22`,
+		`FROM any TO all vms ALLOW tcp PORT 22 PRIORITY 101`,
+		`FROM any TO all vms ALLOW tcp PORT 22 extra`,
+		`FROM any TO tag "www ALLOW tcp PORT 22`,
+	} {
+		if rule, err := parseFWRule(text); err == nil {
+			t.Errorf("expected %q to be rejected, got %q", text, rule.String())
+		}
+	}
+}
+
+func TestSuppressEquivalentFWRule(t *testing.T) {
+	cases := []struct {
+		old, new string
+		suppress bool
+	}{
+		{
+			old:      `FROM (tag "a" OR tag "b") TO all vms ALLOW tcp PORT 22`,
+			new:      `from (tag b or tag a) to all vms allow tcp port 22`,
+			suppress: true,
+		},
+		{
+			old:      `FROM any TO all vms ALLOW tcp (PORT 80 AND PORT 443)`,
+			new:      "FROM any TO all vms ALLOW tcp (PORT 443 AND PORT 80)\n",
+			suppress: true,
+		},
+		{
+			old:      `FROM any TO tag "role" = "www" ALLOW tcp PORTS 8000 - 8080`,
+			new:      `FROM any TO tag role=www ALLOW tcp PORTS 8000-8080`,
+			suppress: true,
+		},
+		{
+			old:      `FROM any TO vm 3d51f2d5-46f2-4da5-bb04-3238f2f64768 ALLOW tcp PORT 80`,
+			new:      `FROM any TO vm 3D51F2D5-46F2-4DA5-BB04-3238F2F64768 ALLOW tcp PORT 080`,
+			suppress: true,
+		},
+		{
+			old:      `FROM ip fd00::1 TO subnet fd00:1::/64 ALLOW icmp6 TYPE 128 CODE 0`,
+			new:      `FROM ip FD00:0:0:0:0:0:0:1 TO subnet FD00:1:0::/64 ALLOW icmp6 TYPE 0128 CODE 00`,
+			suppress: true,
+		},
+		{
+			old:      `FROM any TO tag "www" ALLOW tcp PORT 80`,
+			new:      `FROM any TO tag "www" BLOCK tcp PORT 80`,
+			suppress: false,
+		},
+		{
+			old:      `FROM any TO tag "www" ALLOW tcp PORT 80`,
+			new:      `FROM any TO tag "WWW" ALLOW tcp PORT 80`,
+			suppress: false,
+		},
+		{
+			old:      `FROM any TO tag "www" ALLOW tcp PORT 80`,
+			new:      `FROM any TO tag "www" ALLOW tcp`,
+			suppress: false,
+		},
+		{
+			old:      `FROM any TO (tag "a" AND tag "b") ALLOW tcp PORT 80`,
+			new:      "FROM any TO (tag \"a\" AND tag \"b\") ALLOW tcp PORT 80\n",
+			suppress: true,
+		},
+		{
+			old:      `FROM any TO (tag "a" AND tag "b") ALLOW tcp PORT 80`,
+			new:      `FROM any TO (tag "b" AND tag "a") ALLOW tcp PORT 80`,
+			suppress: false,
+		},
+	}
+
+	for _, c := range cases {
+		if got := suppressEquivalentFWRule("rule", c.old, c.new, nil); got != c.suppress {
+			t.Errorf("expected suppressing %q -> %q to be %t, got %t", c.old, c.new, c.suppress, got)
+		}
+	}
+}
@@ -65,24 +65,40 @@ func Provider() *schema.Provider {
 		},
 
 		DataSourcesMap: map[string]*schema.Resource{
-			"triton_account":        dataSourceAccount(),
-			"triton_datacenter":     dataSourceDataCenter(),
-			"triton_image":          dataSourceImage(),
-			"triton_network":        dataSourceNetwork(),
-			"triton_package":        dataSourcePackage(),
-			"triton_fabric_vlan":    dataSourceFabricVLAN(),
-			"triton_fabric_network": dataSourceFabricNetwork(),
-			"triton_volume":         dataSourceVolume(),
+			"triton_account":                dataSourceAccount(),
+			"triton_datacenter":             dataSourceDataCenter(),
+			"triton_image":                  dataSourceImage(),
+			"triton_images":                 dataSourceImages(),
+			"triton_network":                dataSourceNetwork(),
+			"triton_network_ips":            dataSourceNetworkIPs(),
+			"triton_networks":               dataSourceNetworks(),
+			"triton_package":                dataSourcePackage(),
+			"triton_packages":               dataSourcePackages(),
+			"triton_fabric_vlan":            dataSourceFabricVLAN(),
+			"triton_fabric_vlans":           dataSourceFabricVLANs(),
+			"triton_fabric_network":         dataSourceFabricNetwork(),
+			"triton_fabric_networks":        dataSourceFabricNetworks(),
+			"triton_firewall_rules":         dataSourceFirewallRules(),
+			"triton_firewall_rule_machines": dataSourceFirewallRuleMachines(),
+			"triton_volume":                 dataSourceVolume(),
+			"triton_volume_sizes":           dataSourceVolumeSizes(),
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
 			"triton_fabric":            resourceFabric(),
 			"triton_firewall_rule":     resourceFirewallRule(),
+			"triton_firewall_ruleset":  resourceFirewallRuleset(),
 			"triton_instance_template": resourceInstanceTemplate(),
+			"triton_image":             resourceImage(),
+			"triton_image_clone":       resourceImageClone(),
+			"triton_image_export":      resourceImageExport(),
+			"triton_image_share":       resourceImageShare(),
 			"triton_key":               resourceKey(),
 			"triton_machine":           resourceMachine(),
+			"triton_network_ip":        resourceNetworkIP(),
 			"triton_service_group":     resourceServiceGroup(),
 			"triton_snapshot":          resourceSnapshot(),
+			"triton_snapshot_rollback": resourceSnapshotRollback(),
 			"triton_vlan":              resourceVLAN(),
 			"triton_volume":            resourceVolume(),
 		},
@@ -1,8 +1,12 @@
 package triton
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"log"
+	"net"
+	"net/http"
 	"strconv"
 	"strings"
 
@@ -16,38 +20,21 @@ func resourceFabric() *schema.Resource {
 		Create: resourceFabricCreate,
 		Exists: resourceFabricExists,
 		Read:   resourceFabricRead,
+		Update: resourceFabricUpdate,
 		Delete: resourceFabricDelete,
 		Importer: &schema.ResourceImporter{
-			State: func(d *schema.ResourceData, meta any) ([]*schema.ResourceData, error) {
-				// d.Id() is the last argument passed to the `terraform import RESOURCE_TYPE.RESOURCE_NAME RESOURCE_ID` command
-				// We need to parse both the fabric vlan ID and the fabric UUID to import it
-				vlanIdString, fabricId, err := resourceFabricParseIds(d.Id())
-
-				if err != nil {
-					return nil, err
-				}
-
-				vlanIdInt, err := strconv.Atoi(vlanIdString)
-
-				if err != nil {
-					return nil, err
-				}
-
-				d.Set("vlan_id", vlanIdInt)
-				d.SetId(fabricId)
-
-				return []*schema.ResourceData{d}, nil
-			},
+			State: resourceFabricImport,
 		},
 
 		SchemaVersion: 1,
 		MigrateState:  resourceFabricMigrateState,
 
+		CustomizeDiff: resourceFabricCustomizeDiff,
+
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Description: "Network name",
 				Required:    true,
-				ForceNew:    true,
 				Type:        schema.TypeString,
 			},
 			"public": {
@@ -63,11 +50,10 @@ func resourceFabric() *schema.Resource {
 			"description": {
 				Description: "Description of network",
 				Optional:    true,
-				ForceNew:    true,
 				Type:        schema.TypeString,
 			},
 			"subnet": {
-				Description: "CIDR formatted string describing network address space",
+				Description: "CIDR formatted string describing network address space. Changing this forces a new network",
 				Required:    true,
 				ForceNew:    true,
 				Type:        schema.TypeString,
@@ -75,17 +61,15 @@ func resourceFabric() *schema.Resource {
 			"provision_start_ip": {
 				Description: "First IP on the network that can be assigned",
 				Required:    true,
-				ForceNew:    true,
 				Type:        schema.TypeString,
 			},
 			"provision_end_ip": {
 				Description: "Last assignable IP on the network",
 				Required:    true,
-				ForceNew:    true,
 				Type:        schema.TypeString,
 			},
 			"gateway": {
-				Description: "Gateway IP",
+				Description: "Gateway IP. Changing this forces a new network",
 				Optional:    true,
 				ForceNew:    true,
 				Type:        schema.TypeString,
@@ -101,18 +85,17 @@ func resourceFabric() *schema.Resource {
 				Description: "Map of CIDR block to Gateway IP address",
 				Computed:    true,
 				Optional:    true,
-				ForceNew:    true,
 				Type:        schema.TypeMap,
 			},
 			"internet_nat": {
-				Description: "Whether or not a NAT zone is provisioned at the Gateway IP address",
+				Description: "Whether or not a NAT zone is provisioned at the Gateway IP address. Changing this forces a new network",
 				Default:     true,
 				Optional:    true,
 				ForceNew:    true,
 				Type:        schema.TypeBool,
 			},
 			"vlan_id": {
-				Description: "VLAN on which the network exists",
+				Description: "VLAN on which the network exists. Changing this forces a new network",
 				Required:    true,
 				ForceNew:    true,
 				Type:        schema.TypeInt,
@@ -209,6 +192,84 @@ func resourceFabricRead(d *schema.ResourceData, meta interface{}) error {
 	return nil
 }
 
+// customizeDiffFabricClear plans clearing the resolvers or routes of a
+// network when they are set empty. Both are computed, so leaving them out of
+// the configuration keeps what the network has, and an empty value would not
+// show up as a change otherwise.
+func customizeDiffFabricClear(d *schema.ResourceDiff) error {
+	if d.Id() == "" {
+		return nil
+	}
+
+	config := d.GetRawConfig()
+	cleared := func(key string) bool {
+		v := config.GetAttr(key)
+		return !v.IsNull() && v.IsKnown() && v.LengthInt() == 0
+	}
+
+	if o, _ := d.GetChange("resolvers"); cleared("resolvers") && len(o.([]interface{})) > 0 {
+		if err := d.SetNew("resolvers", []interface{}{}); err != nil {
+			return err
+		}
+	}
+	if o, _ := d.GetChange("routes"); cleared("routes") && len(o.(map[string]interface{})) > 0 {
+		if err := d.SetNew("routes", map[string]interface{}{}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resourceFabricUpdate changes the arguments of a fabric network which
+// CloudAPI allows to change in place. Only the changed ones are sent, so that
+// clearing the description, resolvers or routes is not mistaken for leaving
+// them alone; see customizeDiffFabricClear for the latter two. The others
+// force a new network.
+func resourceFabricUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	n, err := client.Network()
+	if err != nil {
+		return err
+	}
+
+	changes := map[string]interface{}{}
+	for _, key := range []string{"name", "description", "provision_start_ip", "provision_end_ip"} {
+		if d.HasChange(key) {
+			changes[key] = d.Get(key).(string)
+		}
+	}
+
+	if d.HasChange("resolvers") {
+		resolvers := []string{}
+		for _, resolver := range d.Get("resolvers").([]interface{}) {
+			resolvers = append(resolvers, resolver.(string))
+		}
+		changes["resolvers"] = resolvers
+	}
+
+	if d.HasChange("routes") {
+		routes := map[string]string{}
+		for cidr, v := range d.Get("routes").(map[string]interface{}) {
+			ip, ok := v.(string)
+			if !ok {
+				return fmt.Errorf(`cannot use "%v" as an IP address`, v)
+			}
+			routes[cidr] = ip
+		}
+		changes["routes"] = routes
+	}
+
+	if len(changes) > 0 {
+		log.Printf("[DEBUG] Updating fabric network %q on VLAN %d", d.Id(), d.Get("vlan_id").(int))
+		if _, err := updateFabric(context.Background(), n, d.Get("vlan_id").(int), d.Id(), changes); err != nil {
+			return err
+		}
+	}
+
+	return resourceFabricRead(d, meta)
+}
+
 func resourceFabricDelete(d *schema.ResourceData, meta interface{}) error {
 	client := meta.(*Client)
 	n, err := client.Network()
@@ -227,6 +288,240 @@ func resourceFabricDelete(d *schema.ResourceData, meta interface{}) error {
 	return err2
 }
 
+// resourceFabricCustomizeDiff checks the addressing of a fabric network at
+// plan time rather than leaving it to CloudAPI midway through an apply:
+// the provisioning range and gateway have to be in the subnet, the routes
+// have to map subnets to addresses, and the subnet must not overlap another
+// network on the same VLAN.
+func resourceFabricCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if err := customizeDiffFabricClear(d); err != nil {
+		return err
+	}
+
+	for _, key := range []string{"subnet", "provision_start_ip", "provision_end_ip", "gateway", "routes"} {
+		if !d.NewValueKnown(key) {
+			return nil
+		}
+	}
+
+	subnet, err := validateFabricAddressing(
+		d.Get("subnet").(string),
+		d.Get("provision_start_ip").(string),
+		d.Get("provision_end_ip").(string),
+		d.Get("gateway").(string),
+		d.Get("routes").(map[string]interface{}),
+	)
+	if err != nil {
+		return err
+	}
+
+	if d.Id() != "" && !d.HasChange("subnet") && !d.HasChange("vlan_id") {
+		return nil
+	}
+
+	// When the network is replaced the diff is worked out again without the
+	// prior state, but the network being replaced must still not count as
+	// an overlap.
+	id := d.Id()
+	if state := d.GetRawState(); id == "" && !state.IsNull() && state.IsKnown() {
+		if v := state.GetAttr("id"); !v.IsNull() && v.IsKnown() {
+			id = v.AsString()
+		}
+	}
+	if !d.NewValueKnown("vlan_id") {
+		return nil
+	}
+
+	client := meta.(*Client)
+	n, err := client.Network()
+	if err != nil {
+		return err
+	}
+
+	vlanID := d.Get("vlan_id").(int)
+	fabrics, err := n.Fabrics().List(ctx, &network.ListFabricsInput{
+		FabricVLANID: vlanID,
+	})
+	if err != nil {
+		// The VLAN may be created in the same apply, in which case there is
+		// nothing to overlap with yet.
+		if errors.IsSpecificStatusCode(err, http.StatusNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	for _, fabric := range fabrics {
+		if fabric.Id == id {
+			continue
+		}
+		_, other, err := net.ParseCIDR(fabric.Subnet)
+		if err != nil {
+			continue
+		}
+		if subnet.Contains(other.IP) || other.Contains(subnet.IP) {
+			return fmt.Errorf("subnet %s overlaps subnet %s of network %q on VLAN %d", subnet, other, fabric.Name, vlanID)
+		}
+	}
+
+	return nil
+}
+
+// validateFabricAddressing parses the subnet of a fabric network and checks
+// that the other addresses of the network agree with it.
+func validateFabricAddressing(subnet, start, end, gateway string, routes map[string]interface{}) (*net.IPNet, error) {
+	_, ipNet, err := net.ParseCIDR(subnet)
+	if err != nil {
+		return nil, fmt.Errorf("subnet %q is not a valid CIDR block: %s", subnet, err)
+	}
+
+	addresses := []struct {
+		key   string
+		value string
+	}{
+		{"provision_start_ip", start},
+		{"provision_end_ip", end},
+		{"gateway", gateway},
+	}
+	parsed := map[string]net.IP{}
+	for _, address := range addresses {
+		if address.value == "" {
+			continue
+		}
+		ip := net.ParseIP(address.value)
+		if ip == nil {
+			return nil, fmt.Errorf("%s %q is not a valid IP address", address.key, address.value)
+		}
+		if !ipNet.Contains(ip) {
+			return nil, fmt.Errorf("%s %s is not in subnet %s", address.key, ip, ipNet)
+		}
+		parsed[address.key] = ip
+	}
+
+	if start, end := parsed["provision_start_ip"], parsed["provision_end_ip"]; start != nil && end != nil {
+		if bytes.Compare(start.To16(), end.To16()) > 0 {
+			return nil, fmt.Errorf("provision_start_ip %s is after provision_end_ip %s", start, end)
+		}
+	}
+
+	for cidr, v := range routes {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return nil, fmt.Errorf("route %q is not a valid CIDR block: %s", cidr, err)
+		}
+		if ip, ok := v.(string); !ok || net.ParseIP(ip) == nil {
+			return nil, fmt.Errorf("gateway %q of route %s is not a valid IP address", v, cidr)
+		}
+	}
+
+	return ipNet, nil
+}
+
+// resourceFabricImport imports a fabric network by any of
+// `vlanId.fabricId`, `vlanId/networkName`, `vlanName/networkName` or the bare
+// network UUID, in which case the VLAN the network is on is looked up.
+func resourceFabricImport(d *schema.ResourceData, meta any) ([]*schema.ResourceData, error) {
+	client := meta.(*Client)
+	n, err := client.Network()
+	if err != nil {
+		return nil, err
+	}
+
+	vlanID, fabricID, err := resourceFabricResolveImportId(context.Background(), n, d.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	d.Set("vlan_id", vlanID)
+	d.SetId(fabricID)
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceFabricResolveImportId returns the VLAN ID and network UUID of the
+// fabric network an import ID refers to.
+func resourceFabricResolveImportId(ctx context.Context, n *network.NetworkClient, id string) (int, string, error) {
+	if vlan, name, found := strings.Cut(id, "/"); found {
+		if vlan == "" || name == "" {
+			return 0, "", fmt.Errorf("unexpected format of ID (%s), expected vlanId/networkName or vlanName/networkName", id)
+		}
+
+		vlanID, err := strconv.Atoi(vlan)
+		if err != nil {
+			if vlanID, err = resourceFabricFindVLAN(ctx, n, vlan); err != nil {
+				return 0, "", err
+			}
+		}
+
+		fabrics, err := n.Fabrics().List(ctx, &network.ListFabricsInput{
+			FabricVLANID: vlanID,
+		})
+		if err != nil {
+			return 0, "", err
+		}
+		for _, fabric := range fabrics {
+			if fabric.Name == name {
+				return vlanID, fabric.Id, nil
+			}
+		}
+		return 0, "", fmt.Errorf("no network named %q found on VLAN %d", name, vlanID)
+	}
+
+	if strings.Contains(id, ".") {
+		vlan, fabricID, err := resourceFabricParseIds(id)
+		if err != nil {
+			return 0, "", err
+		}
+		vlanID, err := strconv.Atoi(vlan)
+		if err != nil {
+			return 0, "", err
+		}
+		return vlanID, fabricID, nil
+	}
+
+	// A bare network UUID: look for it on every VLAN.
+	vlans, err := n.Fabrics().ListVLANs(ctx, &network.ListVLANsInput{})
+	if err != nil {
+		return 0, "", err
+	}
+	for _, vlan := range vlans {
+		fabrics, err := n.Fabrics().List(ctx, &network.ListFabricsInput{
+			FabricVLANID: vlan.ID,
+		})
+		if err != nil {
+			return 0, "", err
+		}
+		for _, fabric := range fabrics {
+			if fabric.Id == id {
+				return vlan.ID, fabric.Id, nil
+			}
+		}
+	}
+	return 0, "", fmt.Errorf("no fabric network with ID %q found on any VLAN", id)
+}
+
+// resourceFabricFindVLAN returns the ID of the VLAN with the given name.
+func resourceFabricFindVLAN(ctx context.Context, n *network.NetworkClient, name string) (int, error) {
+	vlans, err := n.Fabrics().ListVLANs(ctx, &network.ListVLANsInput{})
+	if err != nil {
+		return 0, err
+	}
+
+	var matches []*network.FabricVLAN
+	for _, vlan := range vlans {
+		if vlan.Name == name {
+			matches = append(matches, vlan)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return 0, fmt.Errorf("no VLAN named %q found", name)
+	case 1:
+		return matches[0].ID, nil
+	default:
+		return 0, fmt.Errorf("%d VLANs are named %q, import by VLAN ID instead", len(matches), name)
+	}
+}
+
 func resourceFabricParseIds(id string) (string, string, error) {
 	parts := strings.SplitN(id, ".", 2)
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -141,3 +142,336 @@ resource "triton_fabric" "test" {
   resolvers = ["8.8.8.8", "8.8.4.4"]
 }
 `
+
+func TestFakeTritonFabric_basic(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	config := f.ProviderConfig() + fmt.Sprintf(testAccTritonFabric_basic, 100, "fake-fabric", "fake-fabric")
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:     func() { testFakePreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckTritonFabricDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckTritonFabricExists("triton_fabric.test"),
+					resource.TestCheckResourceAttr("triton_fabric.test", "fabric", "true"),
+				),
+			},
+			{
+				ResourceName:      "triton_fabric.test",
+				ImportState:       true,
+				ImportStateIdFunc: testAccTritonFabricImportStateIdFunc("triton_fabric.test"),
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestFakeTritonFabric_lifecycle(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+
+	vlan, err := testFakeApply(resourceVLAN(), nil, map[string]interface{}{
+		"vlan_id":     100,
+		"name":        "fake-vlan",
+		"description": "fake VLAN",
+	}, meta)
+	if err != nil {
+		t.Fatalf("error creating VLAN: %s", err)
+	}
+
+	r := resourceFabric()
+	config := map[string]interface{}{
+		"name":               "fake-fabric",
+		"description":        "fake network",
+		"vlan_id":            100,
+		"subnet":             "10.0.0.0/22",
+		"gateway":            "10.0.0.1",
+		"provision_start_ip": "10.0.0.5",
+		"provision_end_ip":   "10.0.3.250",
+		"resolvers":          []interface{}{"8.8.8.8"},
+	}
+
+	state, err := testFakeApply(r, nil, config, meta)
+	if err != nil {
+		t.Fatalf("error creating fabric: %s", err)
+	}
+	if state.Attributes["fabric"] != "true" || state.Attributes["resolvers.0"] != "8.8.8.8" {
+		t.Fatalf("unexpected fabric state: %#v", state.Attributes)
+	}
+
+	if err := testFakeDestroy(resourceVLAN(), vlan, meta); err == nil {
+		t.Fatal("expected deleting a VLAN with networks to fail")
+	}
+
+	if err := testFakeDestroy(r, state, meta); err != nil {
+		t.Fatalf("error destroying fabric: %s", err)
+	}
+	if err := testFakeDestroy(resourceVLAN(), vlan, meta); err != nil {
+		t.Fatalf("error destroying VLAN: %s", err)
+	}
+}
+
+func TestFakeTritonFabric_update(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+
+	if _, err := testFakeApply(resourceVLAN(), nil, map[string]interface{}{
+		"vlan_id": 100,
+		"name":    "fake-vlan",
+	}, meta); err != nil {
+		t.Fatalf("error creating VLAN: %s", err)
+	}
+
+	r := resourceFabric()
+	config := map[string]interface{}{
+		"name":               "fake-fabric",
+		"description":        "fake network",
+		"vlan_id":            100,
+		"subnet":             "10.0.0.0/22",
+		"gateway":            "10.0.0.1",
+		"provision_start_ip": "10.0.0.5",
+		"provision_end_ip":   "10.0.3.250",
+		"resolvers":          []interface{}{"8.8.8.8"},
+	}
+
+	state, err := testFakeApply(r, nil, config, meta)
+	if err != nil {
+		t.Fatalf("error creating fabric: %s", err)
+	}
+	id := state.ID
+
+	config["name"] = "renamed-fabric"
+	config["description"] = ""
+	config["provision_start_ip"] = "10.0.0.10"
+	config["provision_end_ip"] = "10.0.2.250"
+	config["resolvers"] = []interface{}{"8.8.4.4", "1.1.1.1"}
+	config["routes"] = map[string]interface{}{"10.10.0.0/16": "10.0.0.2"}
+
+	diff, err := testFakePlan(r, state, config, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff.RequiresNew() {
+		t.Fatalf("expected the fabric to be updated in place, got %#v", diff)
+	}
+
+	state, err = testFakeApply(r, state, config, meta)
+	if err != nil {
+		t.Fatalf("error updating fabric: %s", err)
+	}
+	if state.ID != id {
+		t.Fatalf("expected the fabric to keep ID %s, got %s", id, state.ID)
+	}
+	n := f.networks[id]
+	if n.Name != "renamed-fabric" || n.Description != "" || n.ProvisioningStartIP != "10.0.0.10" || n.ProvisioningEndIP != "10.0.2.250" {
+		t.Fatalf("unexpected fabric after update: %#v", n.Network)
+	}
+	if len(n.Resolvers) != 2 || n.Resolvers[1] != "1.1.1.1" || n.Routes["10.10.0.0/16"] != "10.0.0.2" {
+		t.Fatalf("unexpected fabric resolvers or routes after update: %#v", n.Network)
+	}
+
+	diff, err = testFakePlan(r, state, config, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected an empty plan after update, got %#v", diff)
+	}
+
+	// Leaving the resolvers and routes out keeps them, setting them empty
+	// clears them.
+	kept := map[string]interface{}{}
+	for k, v := range config {
+		if k != "resolvers" && k != "routes" {
+			kept[k] = v
+		}
+	}
+	diff, err = testFakePlan(r, state, kept, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected leaving resolvers and routes out to keep them, got %#v", diff)
+	}
+
+	config["resolvers"] = []interface{}{}
+	config["routes"] = map[string]interface{}{}
+	state, err = testFakeApply(r, state, config, meta)
+	if err != nil {
+		t.Fatalf("error clearing fabric resolvers and routes: %s", err)
+	}
+	if n := f.networks[id]; len(n.Resolvers) != 0 || len(n.Routes) != 0 {
+		t.Fatalf("expected the resolvers and routes to be cleared, got %#v", n.Network)
+	}
+	diff, err = testFakePlan(r, state, config, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected an empty plan after clearing, got %#v", diff)
+	}
+
+	for key, value := range map[string]interface{}{
+		"subnet":       "10.0.0.0/21",
+		"gateway":      "10.0.0.2",
+		"internet_nat": false,
+		"vlan_id":      101,
+	} {
+		changed := map[string]interface{}{}
+		for k, v := range config {
+			changed[k] = v
+		}
+		changed[key] = value
+
+		diff, err := testFakePlan(r, state, changed, meta)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if attr := diff.Attributes[key]; attr == nil || !attr.RequiresNew {
+			t.Fatalf("expected changing %s to force a new fabric, got %#v", key, attr)
+		}
+	}
+}
+
+func TestValidateFabricAddressing(t *testing.T) {
+	cases := []struct {
+		name    string
+		subnet  string
+		start   string
+		end     string
+		gateway string
+		routes  map[string]interface{}
+		err     string
+	}{
+		{name: "valid", subnet: "10.0.0.0/24", start: "10.0.0.5", end: "10.0.0.250", gateway: "10.0.0.1", routes: map[string]interface{}{"10.1.0.0/16": "10.0.0.2"}},
+		{name: "no gateway", subnet: "10.0.0.0/24", start: "10.0.0.5", end: "10.0.0.5"},
+		{name: "bad subnet", subnet: "10.0.0.0", start: "10.0.0.5", end: "10.0.0.250", err: "not a valid CIDR block"},
+		{name: "start outside", subnet: "10.0.0.0/24", start: "10.0.1.5", end: "10.0.0.250", err: "provision_start_ip 10.0.1.5 is not in subnet"},
+		{name: "end outside", subnet: "10.0.0.0/24", start: "10.0.0.5", end: "10.0.1.250", err: "provision_end_ip 10.0.1.250 is not in subnet"},
+		{name: "gateway outside", subnet: "10.0.0.0/24", start: "10.0.0.5", end: "10.0.0.250", gateway: "192.168.0.1", err: "gateway 192.168.0.1 is not in subnet"},
+		{name: "bad start", subnet: "10.0.0.0/24", start: "10.0.0.500", end: "10.0.0.250", err: "not a valid IP address"},
+		{name: "start after end", subnet: "10.0.0.0/24", start: "10.0.0.250", end: "10.0.0.5", err: "is after provision_end_ip"},
+		{name: "bad route subnet", subnet: "10.0.0.0/24", start: "10.0.0.5", end: "10.0.0.250", routes: map[string]interface{}{"10.1.0.0": "10.0.0.2"}, err: "route \"10.1.0.0\""},
+		{name: "bad route gateway", subnet: "10.0.0.0/24", start: "10.0.0.5", end: "10.0.0.250", routes: map[string]interface{}{"10.1.0.0/16": "gateway"}, err: "gateway \"gateway\" of route"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := validateFabricAddressing(tc.subnet, tc.start, tc.end, tc.gateway, tc.routes)
+			if tc.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Fatalf("expected error containing %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestFakeTritonFabric_overlap(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+
+	for _, vlanID := range []int{100, 101} {
+		if _, err := testFakeApply(resourceVLAN(), nil, map[string]interface{}{
+			"vlan_id": vlanID,
+			"name":    fmt.Sprintf("fake-vlan-%d", vlanID),
+		}, meta); err != nil {
+			t.Fatalf("error creating VLAN: %s", err)
+		}
+	}
+
+	r := resourceFabric()
+	fabric := func(name string, vlanID int, subnet, start, end string) map[string]interface{} {
+		return map[string]interface{}{
+			"name":               name,
+			"vlan_id":            vlanID,
+			"subnet":             subnet,
+			"provision_start_ip": start,
+			"provision_end_ip":   end,
+		}
+	}
+
+	if _, err := testFakeApply(r, nil, fabric("first", 100, "10.0.0.0/24", "10.0.0.5", "10.0.0.250"), meta); err != nil {
+		t.Fatalf("error creating fabric: %s", err)
+	}
+
+	_, err := testFakePlan(r, nil, fabric("second", 100, "10.0.0.128/25", "10.0.0.130", "10.0.0.250"), meta)
+	if err == nil || !strings.Contains(err.Error(), `overlaps subnet 10.0.0.0/24 of network "first"`) {
+		t.Fatalf("expected an overlap error, got %v", err)
+	}
+
+	if _, err := testFakePlan(r, nil, fabric("second", 101, "10.0.0.128/25", "10.0.0.130", "10.0.0.250"), meta); err != nil {
+		t.Fatalf("expected no overlap on another VLAN, got %s", err)
+	}
+	if _, err := testFakePlan(r, nil, fabric("second", 102, "10.0.0.128/25", "10.0.0.130", "10.0.0.250"), meta); err != nil {
+		t.Fatalf("expected no overlap on a VLAN which does not exist yet, got %s", err)
+	}
+
+	state, err := testFakeApply(r, nil, fabric("second", 100, "10.0.1.0/24", "10.0.1.5", "10.0.1.250"), meta)
+	if err != nil {
+		t.Fatalf("error creating fabric: %s", err)
+	}
+	_, err = testFakePlan(r, state, fabric("second", 100, "10.0.0.0/23", "10.0.1.5", "10.0.1.250"), meta)
+	if err == nil || !strings.Contains(err.Error(), `network "first"`) {
+		t.Fatalf("expected an overlap error when growing the subnet, got %v", err)
+	}
+}
+
+func TestFakeTritonFabric_import(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+
+	if _, err := testFakeApply(resourceVLAN(), nil, map[string]interface{}{
+		"vlan_id": 100,
+		"name":    "fake-vlan",
+	}, meta); err != nil {
+		t.Fatalf("error creating VLAN: %s", err)
+	}
+
+	r := resourceFabric()
+	state, err := testFakeApply(r, nil, map[string]interface{}{
+		"name":               "fake-fabric",
+		"vlan_id":            100,
+		"subnet":             "10.0.0.0/24",
+		"provision_start_ip": "10.0.0.5",
+		"provision_end_ip":   "10.0.0.250",
+	}, meta)
+	if err != nil {
+		t.Fatalf("error creating fabric: %s", err)
+	}
+
+	for _, id := range []string{
+		"100." + state.ID,
+		"100/fake-fabric",
+		"fake-vlan/fake-fabric",
+		state.ID,
+	} {
+		d := r.Data(&terraform.InstanceState{ID: id})
+		imported, err := r.Importer.State(d, meta)
+		if err != nil {
+			t.Fatalf("error importing %q: %s", id, err)
+		}
+		if len(imported) != 1 || imported[0].Id() != state.ID || imported[0].Get("vlan_id").(int) != 100 {
+			t.Fatalf("expected %q to import fabric %s on VLAN 100, got %s on VLAN %d", id, state.ID, imported[0].Id(), imported[0].Get("vlan_id").(int))
+		}
+	}
+
+	for _, id := range []string{
+		"100/missing",
+		"missing/fake-fabric",
+		"00000000-0000-0000-0000-000000000000",
+		"/fake-fabric",
+	} {
+		d := r.Data(&terraform.InstanceState{ID: id})
+		if _, err := r.Importer.State(d, meta); err == nil {
+			t.Fatalf("expected importing %q to fail", id)
+		}
+	}
+}
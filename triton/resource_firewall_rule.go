@@ -2,13 +2,19 @@ package triton
 
 import (
 	"context"
-
+	"fmt"
+	"log"
 	"strings"
 
 	"github.com/TritonDataCenter/triton-go/network"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
+// firewallRuleStructuredKeys are the arguments which describe a rule as
+// blocks rather than as FWRULE text in `rule`.
+var firewallRuleStructuredKeys = []string{"from", "to", "action", "protocol"}
+
 func resourceFirewallRule() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceFirewallRuleCreate,
@@ -20,11 +26,17 @@ func resourceFirewallRule() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
+		CustomizeDiff: resourceFirewallRuleCustomizeDiff,
+
 		Schema: map[string]*schema.Schema{
 			"rule": {
-				Description: "firewall rule text",
-				Type:        schema.TypeString,
-				Required:    true,
+				Description:      "firewall rule text",
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ExactlyOneOf:     []string{"rule", "action"},
+				ValidateFunc:     validateFirewallRule,
+				DiffSuppressFunc: suppressEquivalentFWRule,
 				StateFunc: func(v interface{}) string {
 					switch v := v.(type) {
 					case string:
@@ -34,6 +46,61 @@ func resourceFirewallRule() *schema.Resource {
 					}
 				},
 			},
+			"from": {
+				Description:   "Sources of the traffic the rule applies to",
+				Type:          schema.TypeSet,
+				Optional:      true,
+				ConflictsWith: []string{"rule"},
+				RequiredWith:  firewallRuleStructuredKeys,
+				Elem:          firewallRuleTargetResource(),
+			},
+			"to": {
+				Description:   "Destinations of the traffic the rule applies to",
+				Type:          schema.TypeSet,
+				Optional:      true,
+				ConflictsWith: []string{"rule"},
+				RequiredWith:  firewallRuleStructuredKeys,
+				Elem:          firewallRuleTargetResource(),
+			},
+			"action": {
+				Description:   "Whether to `allow` or `block` the traffic",
+				Type:          schema.TypeString,
+				Optional:      true,
+				ExactlyOneOf:  []string{"rule", "action"},
+				RequiredWith:  firewallRuleStructuredKeys,
+				ValidateFunc:  validation.StringInSlice([]string{fwruleActionAllow, fwruleActionBlock}, false),
+				ConflictsWith: []string{"rule"},
+			},
+			"protocol": {
+				Description:   "Protocol of the traffic the rule applies to",
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"rule"},
+				RequiredWith:  firewallRuleStructuredKeys,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Description:  "Name of the protocol",
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringInSlice(fwruleProtocols, false),
+						},
+						"ports": {
+							Description: "TCP or UDP ports, port ranges such as `8000-8080`, or `all`",
+							Type:        schema.TypeSet,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+						"icmp_types": {
+							Description: "ICMP types, types and codes such as `3:4`, or `all`",
+							Type:        schema.TypeSet,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
 			"enabled": {
 				Description: "Indicates if the rule is enabled",
 				Type:        schema.TypeBool,
@@ -45,6 +112,12 @@ func resourceFirewallRule() *schema.Resource {
 				Type:        schema.TypeString,
 				Optional:    true,
 			},
+			"log": {
+				Description: "Indicates if traffic matching the rule is logged",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
 			"global": {
 				Description: "Indicates whether or not the rule is global",
 				Type:        schema.TypeBool,
@@ -61,10 +134,28 @@ func resourceFirewallRuleCreate(d *schema.ResourceData, meta interface{}) error
 		return err
 	}
 
-	rule, err := n.Firewall().CreateRule(context.Background(), &network.CreateRuleInput{
-		Rule:        d.Get("rule").(string),
-		Enabled:     d.Get("enabled").(bool),
-		Description: d.Get("description").(string),
+	text, err := firewallRuleText(d)
+	if err != nil {
+		return err
+	}
+
+	// A rule which is already in place as a global rule is owned by the
+	// operator of the cloud; creating a copy of it would be managing it.
+	rules, err := n.Firewall().ListRules(context.Background(), &network.ListRulesInput{})
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		if rule.Global && suppressEquivalentFWRule("rule", rule.Rule, text, nil) {
+			return fmt.Errorf("firewall rule %q is already in place as global rule %s, which is managed by the operator of the cloud; import it instead to refer to it", text, rule.ID)
+		}
+	}
+
+	rule, err := createFirewallRule(context.Background(), n, map[string]interface{}{
+		"rule":        text,
+		"enabled":     d.Get("enabled").(bool),
+		"description": d.Get("description").(string),
+		"log":         d.Get("log").(bool),
 	})
 	if err != nil {
 		return err
@@ -94,9 +185,7 @@ func resourceFirewallRuleRead(d *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 
-	rule, err := n.Firewall().GetRule(context.Background(), &network.GetRuleInput{
-		ID: d.Id(),
-	})
+	rule, err := getFirewallRule(context.Background(), n, d.Id())
 	if err != nil {
 		return err
 	}
@@ -106,6 +195,26 @@ func resourceFirewallRuleRead(d *schema.ResourceData, meta interface{}) error {
 	d.Set("enabled", rule.Enabled)
 	d.Set("global", rule.Global)
 	d.Set("description", rule.Description)
+	d.Set("log", rule.Log)
+
+	// Rules described with blocks are read back into them from the text
+	// CloudAPI normalized the rule to, so that formatting does not diff.
+	// Blocks which already render to that rule are kept as written, so that
+	// the case of a machine UUID or the spelling of an address or port does
+	// not diff either.
+	if d.Get("action").(string) != "" {
+		parsed, err := parseFWRule(rule.Rule)
+		if err != nil {
+			log.Printf("[WARN] triton_firewall_rule: keeping the blocks of rule %q as they are: %s", d.Id(), err)
+			return nil
+		}
+		parsed.normalize()
+		if current, err := expandFirewallRule(d); err != nil || current.String() != parsed.String() {
+			if err := flattenFirewallRule(d, parsed); err != nil {
+				return err
+			}
+		}
+	}
 
 	return nil
 }
@@ -117,11 +226,20 @@ func resourceFirewallRuleUpdate(d *schema.ResourceData, meta interface{}) error
 		return err
 	}
 
-	_, err = n.Firewall().UpdateRule(context.Background(), &network.UpdateRuleInput{
-		ID:          d.Id(),
-		Rule:        d.Get("rule").(string),
-		Enabled:     d.Get("enabled").(bool),
-		Description: d.Get("description").(string),
+	if d.Get("global").(bool) {
+		return firewallRuleGlobalError(d.Id(), "changed")
+	}
+
+	text, err := firewallRuleText(d)
+	if err != nil {
+		return err
+	}
+
+	_, err = updateFirewallRule(context.Background(), n, d.Id(), map[string]interface{}{
+		"rule":        text,
+		"enabled":     d.Get("enabled").(bool),
+		"description": d.Get("description").(string),
+		"log":         d.Get("log").(bool),
 	})
 	if err != nil {
 		return err
@@ -137,7 +255,238 @@ func resourceFirewallRuleDelete(d *schema.ResourceData, meta interface{}) error
 		return err
 	}
 
+	// A global rule cannot be deleted, and failing would fail the destroy
+	// of the whole configuration, so it is only forgotten.
+	if d.Get("global").(bool) {
+		log.Printf("[WARN] triton_firewall_rule: %q is a global rule, which cannot be deleted; removing it from the state only", d.Id())
+		d.SetId("")
+		return nil
+	}
+
 	return n.Firewall().DeleteRule(context.Background(), &network.DeleteRuleInput{
 		ID: d.Id(),
 	})
 }
+
+// firewallRuleGlobalError explains why a global rule, which can only be
+// imported to refer to it, cannot be managed.
+func firewallRuleGlobalError(id, verb string) error {
+	return fmt.Errorf("firewall rule %s is a global rule, which is managed by the operator of the cloud and cannot be %s; "+
+		"remove it from the Terraform state with `terraform state rm` instead", id, verb)
+}
+
+// firewallRuleTargetResource returns the schema of a `from` or `to` block.
+// Exactly one of its arguments is set, except for `value` which goes with
+// `tag`.
+func firewallRuleTargetResource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"any": {
+				Description: "Any host",
+				Type:        schema.TypeBool,
+				Optional:    true,
+			},
+			"all_vms": {
+				Description: "All machines of the account",
+				Type:        schema.TypeBool,
+				Optional:    true,
+			},
+			"vm": {
+				Description: "UUID of a machine",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"ip": {
+				Description: "IP address",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"subnet": {
+				Description: "Subnet in CIDR notation",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"tag": {
+				Description: "Name of a tag the machines have",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"value": {
+				Description: "Value the tag has, if any value does not do",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+		},
+	}
+}
+
+// resourceFirewallRuleCustomizeDiff checks a rule described with blocks at
+// plan time, and refuses changes to global rules. The text CloudAPI
+// normalizes a rule to is only known once it has been applied.
+func resourceFirewallRuleCustomizeDiff(_ context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	textChanged := false
+	if d.Get("action").(string) != "" && d.NewValueKnown("action") {
+		for _, key := range firewallRuleStructuredKeys {
+			if !d.NewValueKnown(key) {
+				return nil
+			}
+		}
+
+		rule, err := expandFirewallRule(d)
+		if err != nil {
+			return err
+		}
+
+		// Comparing the rendered rules rather than the blocks also avoids
+		// spurious changes in the sets nested in `protocol`.
+		if d.Id() != "" {
+			old, _ := d.GetChange("rule")
+			current, err := normalizeFWRule(old.(string))
+			textChanged = err != nil || current != rule.String()
+		}
+	}
+
+	// Imported global rules are read-only.
+	if d.Id() != "" && d.Get("global").(bool) && (textChanged || d.HasChanges("rule", "enabled", "description", "log")) {
+		return firewallRuleGlobalError(d.Id(), "changed")
+	}
+
+	if textChanged {
+		return d.SetNewComputed("rule")
+	}
+
+	return nil
+}
+
+// firewallRuleText returns the FWRULE text of a rule, rendering it from the
+// blocks if it is described with them.
+func firewallRuleText(d *schema.ResourceData) (string, error) {
+	if d.Get("action").(string) == "" {
+		return d.Get("rule").(string), nil
+	}
+
+	rule, err := expandFirewallRule(d)
+	if err != nil {
+		return "", err
+	}
+	text := rule.String()
+	log.Printf("[DEBUG] Rendered firewall rule %q", text)
+
+	return text, nil
+}
+
+// expandFirewallRule builds a rule from the `from`, `to`, `action` and
+// `protocol` arguments.
+func expandFirewallRule(d interface{ Get(string) interface{} }) (*fwrule, error) {
+	rule := &fwrule{
+		Action: d.Get("action").(string),
+	}
+
+	var err error
+	if rule.From, err = expandFirewallRuleTargets("from", d.Get("from").(*schema.Set).List()); err != nil {
+		return nil, err
+	}
+	if rule.To, err = expandFirewallRuleTargets("to", d.Get("to").(*schema.Set).List()); err != nil {
+		return nil, err
+	}
+
+	if protocols := d.Get("protocol").([]interface{}); len(protocols) > 0 && protocols[0] != nil {
+		protocol := protocols[0].(map[string]interface{})
+		rule.Protocol = protocol["name"].(string)
+		rule.Ports = expandFirewallRuleStrings(protocol["ports"].(*schema.Set))
+		rule.Types = expandFirewallRuleStrings(protocol["icmp_types"].(*schema.Set))
+	}
+
+	if err := rule.validate(); err != nil {
+		return nil, err
+	}
+	rule.normalize()
+
+	return rule, nil
+}
+
+func expandFirewallRuleTargets(key string, raw []interface{}) ([]fwruleTarget, error) {
+	targets := make([]fwruleTarget, 0, len(raw))
+	for _, v := range raw {
+		m, _ := v.(map[string]interface{})
+		if m == nil {
+			return nil, fmt.Errorf("each %s block needs one of any, all_vms, vm, ip, subnet or tag", key)
+		}
+
+		var kinds []fwruleTarget
+		if m["any"].(bool) {
+			kinds = append(kinds, fwruleTarget{Kind: fwruleTargetAny})
+		}
+		if m["all_vms"].(bool) {
+			kinds = append(kinds, fwruleTarget{Kind: fwruleTargetAllVMs})
+		}
+		for _, kind := range []string{fwruleTargetVM, fwruleTargetIP, fwruleTargetSubnet, fwruleTargetTag} {
+			if value := m[kind].(string); value != "" {
+				kinds = append(kinds, fwruleTarget{Kind: kind, Value: value})
+			}
+		}
+		if len(kinds) != 1 {
+			return nil, fmt.Errorf("each %s block needs exactly one of any, all_vms, vm, ip, subnet or tag", key)
+		}
+
+		target := kinds[0]
+		if value := m["value"].(string); value != "" {
+			if target.Kind != fwruleTargetTag {
+				return nil, fmt.Errorf("value can only be set on %s blocks with a tag", key)
+			}
+			target.TagValue = value
+			target.HasTagValue = true
+		}
+		targets = append(targets, target)
+	}
+
+	return targets, nil
+}
+
+func expandFirewallRuleStrings(set *schema.Set) []string {
+	result := make([]string, 0, set.Len())
+	for _, v := range set.List() {
+		result = append(result, v.(string))
+	}
+	return result
+}
+
+// flattenFirewallRule sets the `from`, `to`, `action` and `protocol`
+// arguments from a parsed rule.
+func flattenFirewallRule(d *schema.ResourceData, rule *fwrule) error {
+	if err := d.Set("from", flattenFirewallRuleTargets(rule.From)); err != nil {
+		return err
+	}
+	if err := d.Set("to", flattenFirewallRuleTargets(rule.To)); err != nil {
+		return err
+	}
+	d.Set("action", rule.Action)
+
+	return d.Set("protocol", []interface{}{
+		map[string]interface{}{
+			"name":       rule.Protocol,
+			"ports":      rule.Ports,
+			"icmp_types": rule.Types,
+		},
+	})
+}
+
+func flattenFirewallRuleTargets(targets []fwruleTarget) []interface{} {
+	result := make([]interface{}, 0, len(targets))
+	for _, target := range targets {
+		m := map[string]interface{}{
+			"any":     target.Kind == fwruleTargetAny,
+			"all_vms": target.Kind == fwruleTargetAllVMs,
+			"vm":      "",
+			"ip":      "",
+			"subnet":  "",
+			"tag":     "",
+			"value":   target.TagValue,
+		}
+		if _, ok := m[target.Kind]; ok && target.Value != "" {
+			m[target.Kind] = target.Value
+		}
+		result = append(result, m)
+	}
+	return result
+}
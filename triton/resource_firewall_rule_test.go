@@ -153,6 +153,227 @@ func TestAccTritonFirewallRule_heredoc(t *testing.T) {
 	})
 }
 
+func TestFakeTritonFirewallRule_structured(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+
+	r := resourceFirewallRule()
+	config := map[string]interface{}{
+		"description": "Test-Firewall-Rule",
+		"enabled":     true,
+		"action":      "allow",
+		"from": []interface{}{
+			map[string]interface{}{"subnet": "10.1.0.0/16"},
+			map[string]interface{}{"ip": "10.0.0.1"},
+		},
+		"to": []interface{}{
+			map[string]interface{}{"tag": "role", "value": "www"},
+		},
+		"protocol": []interface{}{
+			map[string]interface{}{
+				"name":  "tcp",
+				"ports": []interface{}{"443", "80", "8000-8080"},
+			},
+		},
+	}
+
+	state, err := testFakeApply(r, nil, config, meta)
+	if err != nil {
+		t.Fatalf("error creating firewall rule: %s", err)
+	}
+	expected := `FROM (ip 10.0.0.1 OR subnet 10.1.0.0/16) TO tag "role" = "www" ALLOW tcp (PORT 80 AND PORT 443 AND PORTS 8000 - 8080)`
+	if got := f.rules[state.ID].Rule; got != expected {
+		t.Fatalf("expected rule %q, got %q", expected, got)
+	}
+	if state.Attributes["rule"] != expected {
+		t.Fatalf("expected rule attribute %q, got %q", expected, state.Attributes["rule"])
+	}
+
+	// CloudAPI hands back its own formatting of the rule, which must not
+	// show up as a diff.
+	f.rules[state.ID].Rule = `from (subnet 10.1.0.0/16 or ip 10.0.0.1) to tag role = www allow tcp (port 80 and ports 8000-8080 and port 443)`
+	state, err = testFakeRefresh(r, state, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff, err := testFakePlan(r, state, config, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected an empty plan after a formatting change, got %#v", diff)
+	}
+
+	config["action"] = "block"
+	state, err = testFakeApply(r, state, config, meta)
+	if err != nil {
+		t.Fatalf("error updating firewall rule: %s", err)
+	}
+	expected = `FROM (ip 10.0.0.1 OR subnet 10.1.0.0/16) TO tag "role" = "www" BLOCK tcp (PORT 80 AND PORT 443 AND PORTS 8000 - 8080)`
+	if got := f.rules[state.ID].Rule; got != expected {
+		t.Fatalf("expected rule %q after update, got %q", expected, got)
+	}
+
+	for name, invalid := range map[string]map[string]interface{}{
+		"two kinds": {"any": true, "ip": "10.0.0.1"},
+		"no kind":   {"value": "www"},
+		"value":     {"vm": "3d51f2d5-46f2-4da5-bb04-3238f2f64768", "value": "www"},
+		"bad ip":    {"ip": "10.0.0.300"},
+	} {
+		config["from"] = []interface{}{invalid}
+		if _, err := testFakePlan(r, state, config, meta); err == nil {
+			t.Errorf("expected the %s from block to be rejected at plan time", name)
+		}
+	}
+}
+
+func TestFakeTritonFirewallRule_normalized(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+
+	r := resourceFirewallRule()
+	config := map[string]interface{}{
+		"description": "Test-Firewall-Rule",
+		"rule":        `FROM (tag "a" OR tag "b") TO all vms ALLOW tcp (PORT 80 AND PORT 443)`,
+		"enabled":     true,
+	}
+
+	state, err := testFakeApply(r, nil, config, meta)
+	if err != nil {
+		t.Fatalf("error creating firewall rule: %s", err)
+	}
+
+	f.rules[state.ID].Rule = `FROM (tag b OR tag a) TO all vms ALLOW tcp (PORT 443 AND PORT 80)`
+	state, err = testFakeRefresh(r, state, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff, err := testFakePlan(r, state, config, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected an empty plan for an equivalent rule, got %#v", diff)
+	}
+
+	config["rule"] = `FROM (tag "a" OR tag "b") TO all vms ALLOW tcp PORT 80`
+	diff, err = testFakePlan(r, state, config, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff.Empty() {
+		t.Fatal("expected a plan for a changed rule")
+	}
+
+	// Rules the provider does not understand are left to CloudAPI, and
+	// compared as they are.
+	config["rule"] = `FROM any TO (tag "a" AND tag "b") ALLOW tcp PORT 80`
+	state, err = testFakeApply(r, state, config, meta)
+	if err != nil {
+		t.Fatalf("error updating firewall rule: %s", err)
+	}
+	if f.rules[state.ID].Rule != config["rule"] {
+		t.Fatalf("expected the rule to be sent as it is, got %q", f.rules[state.ID].Rule)
+	}
+	diff, err = testFakePlan(r, state, config, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected an empty plan for an unchanged rule, got %#v", diff)
+	}
+}
+
+func TestFakeTritonFirewallRule_log(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+
+	r := resourceFirewallRule()
+	config := map[string]interface{}{
+		"description": "Test-Firewall-Rule",
+		"rule":        `FROM any TO all vms BLOCK tcp PORT 23`,
+		"enabled":     true,
+		"log":         true,
+	}
+
+	state, err := testFakeApply(r, nil, config, meta)
+	if err != nil {
+		t.Fatalf("error creating firewall rule: %s", err)
+	}
+	if !f.rules[state.ID].Log || state.Attributes["log"] != "true" {
+		t.Fatalf("expected the rule to log, got %#v", f.rules[state.ID])
+	}
+
+	config["log"] = false
+	config["description"] = ""
+	state, err = testFakeApply(r, state, config, meta)
+	if err != nil {
+		t.Fatalf("error updating firewall rule: %s", err)
+	}
+	if rule := f.rules[state.ID]; rule.Log || rule.Description != "" {
+		t.Fatalf("expected the rule to stop logging and lose its description, got %#v", rule)
+	}
+}
+
+func TestFakeTritonFirewallRule_global(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+
+	f.rules["global"] = &firewallRule{FirewallRule: network.FirewallRule{
+		ID:          "global",
+		Rule:        `FROM any TO all vms ALLOW icmp TYPE 8 CODE 0`,
+		Description: "allow ping",
+		Enabled:     true,
+		Global:      true,
+	}}
+
+	r := resourceFirewallRule()
+	imported, err := r.Importer.State(r.Data(&terraform.InstanceState{ID: "global"}), meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, err := testFakeRefresh(r, imported[0].State(), meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Attributes["global"] != "true" {
+		t.Fatalf("expected the imported rule to be global, got %v", state.Attributes)
+	}
+
+	config := map[string]interface{}{
+		"description": "allow ping",
+		"rule":        `FROM any TO all vms ALLOW icmp TYPE 8 CODE 0`,
+		"enabled":     true,
+	}
+	diff, err := testFakePlan(r, state, config, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected an empty plan for the imported global rule, got %#v", diff)
+	}
+
+	config["enabled"] = false
+	if _, err := testFakePlan(r, state, config, meta); err == nil || !strings.Contains(err.Error(), "is a global rule") {
+		t.Fatalf("expected changing a global rule to be refused at plan time, got %v", err)
+	}
+
+	// Destroying a global rule only removes it from the state, so that the
+	// rest of the configuration can still be destroyed.
+	if err := testFakeDestroy(r, state, meta); err != nil {
+		t.Fatalf("expected destroying a global rule to succeed, got %s", err)
+	}
+	if _, found := f.rules["global"]; !found {
+		t.Fatal("expected the global rule to be left in place")
+	}
+
+	config["enabled"] = true
+	config["rule"] = `from any to all vms allow icmp type 8 code 0`
+	if _, err := testFakeApply(r, nil, config, meta); err == nil || !strings.Contains(err.Error(), "global rule global") {
+		t.Fatalf("expected creating a copy of a global rule to be refused, got %v", err)
+	}
+}
+
 func testCheckTritonFirewallRuleExists(name string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		// Ensure we have enough information in state to look up in API
@@ -245,3 +466,51 @@ EOS
 	description = "Test-Firewall-Rule"
 }
 `
+
+func TestFakeTritonFirewallRule_canonical(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+
+	r := resourceFirewallRule()
+	config := map[string]interface{}{
+		"enabled": true,
+		"action":  "allow",
+		"from": []interface{}{
+			map[string]interface{}{"ip": "FD00:0:0:0:0:0:0:1"},
+		},
+		"to": []interface{}{
+			map[string]interface{}{"vm": "3D51F2D5-46F2-4DA5-BB04-3238F2F64768"},
+		},
+		"protocol": []interface{}{
+			map[string]interface{}{
+				"name":  "tcp",
+				"ports": []interface{}{"080", "8000-08080"},
+			},
+		},
+	}
+
+	state, err := testFakeApply(r, nil, config, meta)
+	if err != nil {
+		t.Fatalf("error creating firewall rule: %s", err)
+	}
+	expected := `FROM ip fd00::1 TO vm 3d51f2d5-46f2-4da5-bb04-3238f2f64768 ALLOW tcp (PORT 80 AND PORTS 8000 - 8080)`
+	if got := f.rules[state.ID].Rule; got != expected {
+		t.Fatalf("expected rule %q, got %q", expected, got)
+	}
+
+	// The blocks keep the spelling of the configuration, so that neither
+	// they nor the rule are planned to change.
+	for i := 0; i < 2; i++ {
+		diff, err := testFakePlan(r, state, config, meta)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !diff.Empty() {
+			t.Fatalf("expected an empty plan for a mixed-case UUID, got %#v", diff)
+		}
+		state, err = testFakeRefresh(r, state, meta)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,341 @@
+package triton
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/TritonDataCenter/triton-go/network"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	firewallRulesetByDescriptionPrefix = "description_prefix"
+	firewallRulesetByTag               = "tag"
+)
+
+func resourceFirewallRuleset() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceFirewallRulesetCreate,
+		Read:   resourceFirewallRulesetRead,
+		Update: resourceFirewallRulesetUpdate,
+		Delete: resourceFirewallRulesetDelete,
+		Importer: &schema.ResourceImporter{
+			State: func(d *schema.ResourceData, meta any) ([]*schema.ResourceData, error) {
+				kind, value, err := resourceFirewallRulesetParseId(d.Id())
+				if err != nil {
+					return nil, err
+				}
+
+				d.Set(kind, value)
+
+				return []*schema.ResourceData{d}, nil
+			},
+		},
+
+		Schema: map[string]*schema.Schema{
+			firewallRulesetByDescriptionPrefix: {
+				Description:  "Prefix of the descriptions of the rules which belong to the rule set",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{firewallRulesetByDescriptionPrefix, firewallRulesetByTag},
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			firewallRulesetByTag: {
+				Description:  "Tag, given as `[tag]` at the end of their descriptions, of the rules which belong to the rule set",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{firewallRulesetByDescriptionPrefix, firewallRulesetByTag},
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"rule": {
+				Description: "Rules of the rule set",
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Set:         firewallRulesetRuleHash,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"rule": {
+							Description:      "firewall rule text",
+							Type:             schema.TypeString,
+							Required:         true,
+							ValidateFunc:     validateFirewallRule,
+							DiffSuppressFunc: suppressEquivalentFWRule,
+						},
+						"description": {
+							Description: "Human-readable description of the rule, without the prefix or tag of the rule set",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"enabled": {
+							Description: "Indicates if the rule is enabled",
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+						},
+						"id": {
+							Description: "Identifier of the rule",
+							Type:        schema.TypeString,
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// firewallRuleset tells the rules of a rule set apart from others by their
+// descriptions.
+type firewallRuleset struct {
+	kind  string
+	value string
+}
+
+func expandFirewallRuleset(d *schema.ResourceData) firewallRuleset {
+	if prefix := d.Get(firewallRulesetByDescriptionPrefix).(string); prefix != "" {
+		return firewallRuleset{kind: firewallRulesetByDescriptionPrefix, value: prefix}
+	}
+	return firewallRuleset{kind: firewallRulesetByTag, value: d.Get(firewallRulesetByTag).(string)}
+}
+
+func (s firewallRuleset) id() string {
+	return s.kind + ":" + s.value
+}
+
+// description returns the description CloudAPI stores for a rule of the set.
+func (s firewallRuleset) description(description string) string {
+	if s.kind == firewallRulesetByDescriptionPrefix {
+		return s.value + description
+	}
+	marker := "[" + s.value + "]"
+	if description == "" {
+		return marker
+	}
+	return description + " " + marker
+}
+
+// member reports whether a rule belongs to the set, and returns its
+// description without the prefix or tag if it does.
+func (s firewallRuleset) member(rule *network.FirewallRule) (string, bool) {
+	if rule.Global {
+		return "", false
+	}
+	if s.kind == firewallRulesetByDescriptionPrefix {
+		return strings.CutPrefix(rule.Description, s.value)
+	}
+	description, ok := strings.CutSuffix(rule.Description, "["+s.value+"]")
+	if !ok {
+		return "", false
+	}
+	if description != "" && !strings.HasSuffix(description, " ") {
+		return "", false
+	}
+	return strings.TrimSuffix(description, " "), true
+}
+
+func resourceFirewallRulesetParseId(id string) (string, string, error) {
+	kind, value, ok := strings.Cut(id, ":")
+	if !ok || value == "" || (kind != firewallRulesetByDescriptionPrefix && kind != firewallRulesetByTag) {
+		return "", "", fmt.Errorf("invalid firewall rule set ID %q, expected %s:<prefix> or %s:<tag>", id, firewallRulesetByDescriptionPrefix, firewallRulesetByTag)
+	}
+	return kind, value, nil
+}
+
+// firewallRulesetRuleHash hashes a rule by its normalized text, so that the
+// form CloudAPI stores a rule in does not make it a different element.
+func firewallRulesetRuleHash(v interface{}) int {
+	m := v.(map[string]interface{})
+	text, err := normalizeFWRule(m["rule"].(string))
+	if err != nil {
+		text = strings.TrimSpace(m["rule"].(string))
+	}
+	description, _ := m["description"].(string)
+	enabled, _ := m["enabled"].(bool)
+	return hashcodeString(text + "\x00" + description + "\x00" + strconv.FormatBool(enabled))
+}
+
+// firewallRulesetRuleKey identifies a rule of the set by its normalized text
+// and description, but not by whether it is enabled.
+func firewallRulesetRuleKey(text, description string) string {
+	if normalized, err := normalizeFWRule(text); err == nil {
+		text = normalized
+	}
+	return text + "\x00" + description
+}
+
+// listFirewallRulesetMembers returns the rules which belong to the set.
+func listFirewallRulesetMembers(ctx context.Context, n *network.NetworkClient, set firewallRuleset) ([]*network.FirewallRule, error) {
+	rules, err := n.Firewall().ListRules(ctx, &network.ListRulesInput{})
+	if err != nil {
+		return nil, err
+	}
+
+	var members []*network.FirewallRule
+	for _, rule := range rules {
+		if _, ok := set.member(rule); ok {
+			members = append(members, rule)
+		}
+	}
+	return members, nil
+}
+
+func resourceFirewallRulesetCreate(d *schema.ResourceData, meta interface{}) error {
+	d.SetId(expandFirewallRuleset(d).id())
+
+	if err := resourceFirewallRulesetReconcile(d, meta); err != nil {
+		return err
+	}
+
+	return resourceFirewallRulesetRead(d, meta)
+}
+
+func resourceFirewallRulesetRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	n, err := client.Network()
+	if err != nil {
+		return err
+	}
+
+	set := expandFirewallRuleset(d)
+	members, err := listFirewallRulesetMembers(context.Background(), n, set)
+	if err != nil {
+		return err
+	}
+
+	rules := make([]interface{}, 0, len(members))
+	for _, rule := range members {
+		description, _ := set.member(rule)
+		rules = append(rules, map[string]interface{}{
+			"id":          rule.ID,
+			"rule":        rule.Rule,
+			"description": description,
+			"enabled":     rule.Enabled,
+		})
+	}
+	log.Printf("[DEBUG] triton_firewall_ruleset: found %d rules in %q", len(rules), set.id())
+
+	return d.Set("rule", rules)
+}
+
+func resourceFirewallRulesetUpdate(d *schema.ResourceData, meta interface{}) error {
+	if err := resourceFirewallRulesetReconcile(d, meta); err != nil {
+		return err
+	}
+
+	return resourceFirewallRulesetRead(d, meta)
+}
+
+// resourceFirewallRulesetReconcile brings the rules of the set in line with
+// the configuration. Missing rules are created disabled and only enabled once
+// all of them exist; rules which are no longer wanted are disabled or
+// deleted last, so that traffic is never allowed or blocked by half of a
+// change.
+func resourceFirewallRulesetReconcile(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	n, err := client.Network()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	set := expandFirewallRuleset(d)
+	members, err := listFirewallRulesetMembers(ctx, n, set)
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string][]*network.FirewallRule, len(members))
+	for _, rule := range members {
+		description, _ := set.member(rule)
+		key := firewallRulesetRuleKey(rule.Rule, description)
+		existing[key] = append(existing[key], rule)
+	}
+
+	var enable, disable []string
+	for _, v := range d.Get("rule").(*schema.Set).List() {
+		m := v.(map[string]interface{})
+		text := m["rule"].(string)
+		description := m["description"].(string)
+		enabled := m["enabled"].(bool)
+
+		key := firewallRulesetRuleKey(text, description)
+		if rules := existing[key]; len(rules) > 0 {
+			rule := rules[0]
+			existing[key] = rules[1:]
+			switch {
+			case enabled && !rule.Enabled:
+				enable = append(enable, rule.ID)
+			case !enabled && rule.Enabled:
+				disable = append(disable, rule.ID)
+			}
+			continue
+		}
+
+		log.Printf("[DEBUG] triton_firewall_ruleset: creating rule %q in %q", text, set.id())
+		rule, err := n.Firewall().CreateRule(ctx, &network.CreateRuleInput{
+			Rule:        text,
+			Enabled:     false,
+			Description: set.description(description),
+		})
+		if err != nil {
+			return err
+		}
+		if enabled {
+			enable = append(enable, rule.ID)
+		}
+	}
+
+	for _, id := range enable {
+		log.Printf("[DEBUG] triton_firewall_ruleset: enabling rule %q", id)
+		if _, err := n.Firewall().EnableRule(ctx, &network.EnableRuleInput{ID: id}); err != nil {
+			return err
+		}
+	}
+
+	for _, id := range disable {
+		log.Printf("[DEBUG] triton_firewall_ruleset: disabling rule %q", id)
+		if _, err := n.Firewall().DisableRule(ctx, &network.DisableRuleInput{ID: id}); err != nil {
+			return err
+		}
+	}
+
+	for _, rules := range existing {
+		for _, rule := range rules {
+			log.Printf("[DEBUG] triton_firewall_ruleset: deleting rule %q", rule.ID)
+			if err := n.Firewall().DeleteRule(ctx, &network.DeleteRuleInput{ID: rule.ID}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func resourceFirewallRulesetDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	n, err := client.Network()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	members, err := listFirewallRulesetMembers(ctx, n, expandFirewallRuleset(d))
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range members {
+		log.Printf("[DEBUG] triton_firewall_ruleset: deleting rule %q", rule.ID)
+		if err := n.Firewall().DeleteRule(ctx, &network.DeleteRuleInput{ID: rule.ID}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,294 @@
+package triton
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/TritonDataCenter/triton-go/network"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccTritonFirewallRuleset_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckTritonFirewallRulesetDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTritonFirewallRuleset_basic,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("triton_firewall_ruleset.test", "rule.#", "2"),
+				),
+			},
+			{
+				Config: testAccTritonFirewallRuleset_update,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("triton_firewall_ruleset.test", "rule.#", "1"),
+				),
+			},
+			{
+				ResourceName:      "triton_firewall_ruleset.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testCheckTritonFirewallRulesetDestroy(s *terraform.State) error {
+	conn := testAccProvider.Meta().(*Client)
+	n, err := conn.Network()
+	if err != nil {
+		return err
+	}
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "triton_firewall_ruleset" {
+			continue
+		}
+
+		kind, value, err := resourceFirewallRulesetParseId(rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+		members, err := listFirewallRulesetMembers(context.Background(), n, firewallRuleset{kind: kind, value: value})
+		if err != nil {
+			return err
+		}
+
+		if len(members) > 0 {
+			return fmt.Errorf("Bad: Firewall Rule Set %q still has %d rules", rs.Primary.ID, len(members))
+		}
+	}
+
+	return nil
+}
+
+var testAccTritonFirewallRuleset_basic = `
+resource "triton_firewall_ruleset" "test" {
+	description_prefix = "Test-Firewall-Rule-Set: "
+
+	rule {
+		rule = "FROM any TO tag \"www\" ALLOW tcp (PORT 80 AND PORT 443)"
+		description = "web"
+	}
+
+	rule {
+		rule = "FROM any TO all vms BLOCK tcp PORT 143"
+		description = "imap"
+	}
+}
+`
+
+var testAccTritonFirewallRuleset_update = `
+resource "triton_firewall_ruleset" "test" {
+	description_prefix = "Test-Firewall-Rule-Set: "
+
+	rule {
+		rule = "FROM any TO tag \"www\" ALLOW tcp (PORT 80 AND PORT 443)"
+		description = "web"
+	}
+}
+`
+
+func TestFakeTritonFirewallRuleset_reconcile(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+
+	// A rule which does not belong to the set must be left alone.
+	f.rules["other"] = &firewallRule{FirewallRule: network.FirewallRule{
+		ID:          "other",
+		Rule:        "FROM any TO all vms ALLOW tcp PORT 22",
+		Description: "ssh",
+		Enabled:     true,
+	}}
+
+	r := resourceFirewallRuleset()
+	config := map[string]interface{}{
+		"tag": "web-policy",
+		"rule": []interface{}{
+			map[string]interface{}{
+				"rule":        `FROM any TO tag "www" ALLOW tcp PORT 80`,
+				"description": "http",
+			},
+			map[string]interface{}{
+				"rule":        `FROM any TO tag "www" ALLOW tcp PORT 443`,
+				"description": "https",
+			},
+		},
+	}
+
+	state, err := testFakeApply(r, nil, config, meta)
+	if err != nil {
+		t.Fatalf("error creating firewall rule set: %s", err)
+	}
+	if state.ID != "tag:web-policy" {
+		t.Fatalf("unexpected firewall rule set ID %q", state.ID)
+	}
+	var http *firewallRule
+	for _, rule := range f.rules {
+		if rule.Description == "http [web-policy]" {
+			http = rule
+		}
+	}
+	if len(f.rules) != 3 || http == nil || !http.Enabled {
+		t.Fatalf("unexpected rules after create: %#v", f.rules)
+	}
+
+	diff, err := testFakePlan(r, state, config, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected an empty plan after create, got %#v", diff)
+	}
+
+	// Replace the https rule; the new rule has to be in place and enabled
+	// before the old one goes away.
+	config["rule"] = []interface{}{
+		map[string]interface{}{
+			"rule":        `FROM any TO tag "www" ALLOW tcp PORT 80`,
+			"description": "http",
+		},
+		map[string]interface{}{
+			"rule":        `FROM any TO tag "www" ALLOW tcp PORT 8443`,
+			"description": "https",
+		},
+	}
+	f.requests = nil
+	state, err = testFakeApply(r, state, config, meta)
+	if err != nil {
+		t.Fatalf("error updating firewall rule set: %s", err)
+	}
+	var calls []string
+	for _, request := range f.requests {
+		switch parts := strings.Split(request, "/"); {
+		case strings.HasPrefix(request, "GET "):
+		case len(parts) == 3:
+			calls = append(calls, parts[2])
+		case parts[0] == "POST fwrules":
+			calls = append(calls, "create")
+		case parts[0] == "DELETE fwrules":
+			calls = append(calls, "delete")
+		default:
+			calls = append(calls, request)
+		}
+	}
+	if strings.Join(calls, ", ") != "create, enable, delete" {
+		t.Fatalf("expected the new rule to be created, then enabled, then the old rule deleted, got %v", f.requests)
+	}
+	if len(f.rules) != 3 || f.rules[http.ID] == nil {
+		t.Fatalf("unexpected rules after update: %#v", f.rules)
+	}
+	for _, rule := range f.rules {
+		if !rule.Enabled {
+			t.Fatalf("expected rule %q to be enabled", rule.Description)
+		}
+	}
+
+	// Rules added to the set outside of Terraform are drift, which the
+	// next apply removes.
+	f.rules["extra"] = &firewallRule{FirewallRule: network.FirewallRule{
+		ID:          "extra",
+		Rule:        "FROM any TO all vms ALLOW tcp PORT 25",
+		Description: "smtp [web-policy]",
+		Enabled:     true,
+	}}
+	state, err = testFakeRefresh(r, state, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff, err = testFakePlan(r, state, config, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff.Empty() {
+		t.Fatal("expected a plan removing the rule added out of band")
+	}
+	if _, err := testFakeApply(r, state, config, meta); err != nil {
+		t.Fatalf("error reconciling firewall rule set: %s", err)
+	}
+	if _, found := f.rules["extra"]; found {
+		t.Fatal("expected the rule added out of band to be deleted")
+	}
+
+	if err := testFakeDestroy(r, state, meta); err != nil {
+		t.Fatalf("error destroying firewall rule set: %s", err)
+	}
+	if len(f.rules) != 1 || f.rules["other"] == nil {
+		t.Fatalf("expected only the unrelated rule to be left, got %#v", f.rules)
+	}
+}
+
+func TestFakeTritonFirewallRuleset_emptySelector(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+
+	// An empty prefix or tag would take every rule of the account.
+	r := resourceFirewallRuleset()
+	for _, key := range []string{firewallRulesetByDescriptionPrefix, firewallRulesetByTag} {
+		config := map[string]interface{}{
+			key: "",
+			"rule": []interface{}{
+				map[string]interface{}{"rule": "FROM any TO all vms ALLOW tcp PORT 22"},
+			},
+		}
+		_, err := testFakePlan(r, nil, config, meta)
+		if err == nil || !strings.Contains(err.Error(), "not be an empty string") {
+			t.Fatalf("expected an empty %s to be rejected, got %v", key, err)
+		}
+	}
+}
+
+func TestFakeTritonFirewallRuleset_normalized(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+
+	r := resourceFirewallRuleset()
+	config := map[string]interface{}{
+		"description_prefix": "web: ",
+		"rule": []interface{}{
+			map[string]interface{}{
+				"rule":    `FROM (tag "a" OR tag "b") TO all vms ALLOW tcp (PORT 80 AND PORT 443)`,
+				"enabled": false,
+			},
+		},
+	}
+
+	state, err := testFakeApply(r, nil, config, meta)
+	if err != nil {
+		t.Fatalf("error creating firewall rule set: %s", err)
+	}
+	for _, rule := range f.rules {
+		if rule.Enabled || rule.Description != "web: " {
+			t.Fatalf("unexpected rule after create: %#v", rule)
+		}
+		rule.Rule = `FROM (tag b OR tag a) TO all vms ALLOW tcp (PORT 443 AND PORT 80)`
+	}
+
+	state, err = testFakeRefresh(r, state, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff, err := testFakePlan(r, state, config, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected an empty plan for an equivalent rule, got %#v", diff)
+	}
+
+	imported, err := r.Importer.State(r.Data(&terraform.InstanceState{ID: "description_prefix:web: "}), meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := imported[0].Get("description_prefix").(string); got != "web: " {
+		t.Fatalf("expected the import to set description_prefix, got %q", got)
+	}
+	if _, err := r.Importer.State(r.Data(&terraform.InstanceState{ID: "web"}), meta); err == nil {
+		t.Fatal("expected an import ID without a kind to be rejected")
+	}
+}
@@ -0,0 +1,300 @@
+package triton
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/TritonDataCenter/triton-go/compute"
+	"github.com/TritonDataCenter/triton-go/errors"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	imageStateActive   = "active"
+	imageStateCreating = "creating"
+	imageStateFailed   = "failed"
+
+	imageCreateTimeout = 30 * time.Minute
+)
+
+func resourceImage() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceImageCreate,
+		Exists: resourceImageExists,
+		Read:   resourceImageRead,
+		Update: resourceImageUpdate,
+		Delete: resourceImageDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		CustomizeDiff: resourceImageCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			"machine_id": {
+				Description: "The ID of the stopped machine from which to create the image.",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				// CloudAPI does not tell which machine an image was
+				// created from, so an imported image has none.
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					return old == "" && d.Id() != ""
+				},
+			},
+			"name": {
+				Description: "The name of the image.",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			"version": {
+				Description: "The version of the image.",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			"description": {
+				Description: "A short description of the image.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"homepage": {
+				Description: "Homepage URL where users can find more information about the image.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"eula": {
+				Description: "URL of the End User License Agreement (EULA) for the image.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"acl": {
+				Description: "Account UUIDs given access to this private image.",
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"tags": {
+				Description: "Image tags.",
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+
+			// Computed parameters
+			"os": {
+				Description: "The underlying operating system of the image.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"type": {
+				Description: "The image type.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"owner": {
+				Description: "The UUID of the account which owns the image.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"public": {
+				Description: "Whether the image is public.",
+				Type:        schema.TypeBool,
+				Computed:    true,
+			},
+			"state": {
+				Description: "The current state of the image.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"published_at": {
+				Description: "When the image was published.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceImageCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	c, err := client.Compute()
+	if err != nil {
+		return err
+	}
+
+	var acl []string
+	for _, account := range d.Get("acl").(*schema.Set).List() {
+		acl = append(acl, account.(string))
+	}
+
+	tags := map[string]string{}
+	for k, v := range d.Get("tags").(map[string]interface{}) {
+		tags[k] = v.(string)
+	}
+
+	image, err := c.Images().CreateFromMachine(context.Background(), &compute.CreateImageFromMachineInput{
+		MachineID:   d.Get("machine_id").(string),
+		Name:        d.Get("name").(string),
+		Version:     d.Get("version").(string),
+		Description: d.Get("description").(string),
+		HomePage:    d.Get("homepage").(string),
+		EULA:        d.Get("eula").(string),
+		ACL:         acl,
+		Tags:        tags,
+	})
+	if err != nil {
+		return err
+	}
+
+	d.SetId(image.ID)
+
+	stateConf := &retry.StateChangeConf{
+		Pending: []string{imageStateCreating, "unactivated"},
+		Target:  []string{imageStateActive},
+		Refresh: func() (interface{}, string, error) {
+			image, err := c.Images().Get(context.Background(), &compute.GetImageInput{
+				ImageID: d.Id(),
+			})
+			if err != nil {
+				return nil, "", err
+			}
+			if image.State == imageStateFailed {
+				d.SetId("")
+				return nil, "", fmt.Errorf("image creation failed: %s", image.State)
+			}
+
+			return image, image.State, nil
+		},
+		Timeout:    imageCreateTimeout,
+		MinTimeout: defaultPollInterval,
+	}
+	_, err = stateConf.WaitForState()
+	if err != nil {
+		return err
+	}
+
+	return resourceImageRead(d, meta)
+}
+
+func resourceImageExists(d *schema.ResourceData, meta interface{}) (bool, error) {
+	client := meta.(*Client)
+	c, err := client.Compute()
+	if err != nil {
+		return false, err
+	}
+
+	return resourceExists(c.Images().Get(context.Background(), &compute.GetImageInput{
+		ImageID: d.Id(),
+	}))
+}
+
+func resourceImageRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	c, err := client.Compute()
+	if err != nil {
+		return err
+	}
+
+	image, err := c.Images().Get(context.Background(), &compute.GetImageInput{
+		ImageID: d.Id(),
+	})
+	if err != nil {
+		if errors.IsSpecificStatusCode(err, http.StatusNotFound) || errors.IsSpecificStatusCode(err, http.StatusGone) {
+			log.Printf("[DEBUG] Image %q not found or has been deleted", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	d.Set("name", image.Name)
+	d.Set("version", image.Version)
+	d.Set("description", image.Description)
+	d.Set("homepage", image.Homepage)
+	d.Set("eula", image.EULA)
+	d.Set("acl", image.ACL)
+	d.Set("tags", image.Tags)
+	d.Set("os", image.OS)
+	d.Set("type", image.Type)
+	d.Set("owner", image.Owner)
+	d.Set("public", image.Public)
+	d.Set("state", image.State)
+	d.Set("published_at", image.PublishedAt.Format(time.RFC3339))
+
+	return nil
+}
+
+// resourceImageCustomizeDiff plans clearing the ACL of an image when `acl` is
+// set empty. The ACL is computed, so that leaving `acl` out leaves it to
+// triton_image_share resources, and an empty set would not show up as a
+// change otherwise.
+func resourceImageCustomizeDiff(_ context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" {
+		return nil
+	}
+
+	acl := d.GetRawConfig().GetAttr("acl")
+	if acl.IsNull() || !acl.IsKnown() || acl.LengthInt() > 0 {
+		return nil
+	}
+	if o, _ := d.GetChange("acl"); o.(*schema.Set).Len() == 0 {
+		return nil
+	}
+
+	return d.SetNew("acl", []interface{}{})
+}
+
+func resourceImageUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	c, err := client.Compute()
+	if err != nil {
+		return err
+	}
+
+	fields := map[string]interface{}{}
+	for _, key := range []string{"name", "version", "description", "homepage", "eula"} {
+		if d.HasChange(key) {
+			fields[key] = d.Get(key).(string)
+		}
+	}
+	if d.HasChange("acl") {
+		acl := []string{}
+		for _, account := range d.Get("acl").(*schema.Set).List() {
+			acl = append(acl, account.(string))
+		}
+		fields["acl"] = acl
+	}
+	if d.HasChange("tags") {
+		tags := map[string]string{}
+		for k, v := range d.Get("tags").(map[string]interface{}) {
+			tags[k] = v.(string)
+		}
+		fields["tags"] = tags
+	}
+
+	if len(fields) > 0 {
+		log.Printf("[DEBUG] Updating image %q: %v", d.Id(), fields)
+		if _, err := updateImage(context.Background(), c, d.Id(), fields); err != nil {
+			return err
+		}
+	}
+
+	return resourceImageRead(d, meta)
+}
+
+func resourceImageDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	c, err := client.Compute()
+	if err != nil {
+		return err
+	}
+
+	return c.Images().Delete(context.Background(), &compute.DeleteImageInput{
+		ImageID: d.Id(),
+	})
+}
@@ -0,0 +1,115 @@
+package triton
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/TritonDataCenter/triton-go/compute"
+	"github.com/TritonDataCenter/triton-go/errors"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceImageClone() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceImageCloneCreate,
+		Exists: resourceImageExists,
+		Read:   resourceImageCloneRead,
+		Delete: resourceImageDelete,
+
+		Schema: map[string]*schema.Schema{
+			"source_image_id": {
+				Description: "The ID of an image shared with this account to clone.",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+
+			// Computed parameters
+			"name": {
+				Description: "The name of the cloned image.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"version": {
+				Description: "The version of the cloned image.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"os": {
+				Description: "The underlying operating system of the cloned image.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"type": {
+				Description: "The type of the cloned image.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"owner": {
+				Description: "The UUID of the account which owns the cloned image.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"state": {
+				Description: "The current state of the cloned image.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"published_at": {
+				Description: "When the cloned image was published.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceImageCloneCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	c, err := client.Compute()
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] Cloning image %q", d.Get("source_image_id"))
+	image, err := cloneImage(context.Background(), c, d.Get("source_image_id").(string))
+	if err != nil {
+		return err
+	}
+
+	d.SetId(image.ID)
+
+	return resourceImageCloneRead(d, meta)
+}
+
+func resourceImageCloneRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	c, err := client.Compute()
+	if err != nil {
+		return err
+	}
+
+	image, err := c.Images().Get(context.Background(), &compute.GetImageInput{
+		ImageID: d.Id(),
+	})
+	if err != nil {
+		if errors.IsSpecificStatusCode(err, http.StatusNotFound) || errors.IsSpecificStatusCode(err, http.StatusGone) {
+			log.Printf("[DEBUG] Image %q not found or has been deleted", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	d.Set("name", image.Name)
+	d.Set("version", image.Version)
+	d.Set("os", image.OS)
+	d.Set("type", image.Type)
+	d.Set("owner", image.Owner)
+	d.Set("state", image.State)
+	d.Set("published_at", image.PublishedAt.Format(time.RFC3339))
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package triton
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccTritonImageClone_basic(t *testing.T) {
+	sharedImageID := testAccConfig(t, "shared_image_id")
+	if sharedImageID == "" {
+		t.Skip("testacc_shared_image_id must be set to test image cloning")
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckTritonImageDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTritonImageClone_basic(sharedImageID),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("triton_image_clone.test", "state", "active"),
+					resource.TestCheckResourceAttrSet("triton_image_clone.test", "owner"),
+				),
+			},
+		},
+	})
+}
+
+var testAccTritonImageClone_basic = func(sharedImageID string) string {
+	return fmt.Sprintf(`
+		resource "triton_image_clone" "test" {
+			source_image_id = "%s"
+		}
+	`, sharedImageID)
+}
+
+func TestFakeTritonImageClone_basic(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+	r := resourceImageClone()
+
+	state, err := testFakeApply(r, nil, map[string]interface{}{
+		"source_image_id": fakeImageSharedID,
+	}, meta)
+	if err != nil {
+		t.Fatalf("error cloning image: %s", err)
+	}
+	if state.ID == fakeImageSharedID {
+		t.Fatal("expected the clone to be a new image")
+	}
+	for k, v := range map[string]string{
+		"name":    "partner-base",
+		"version": "2.1.0",
+		"owner":   fakeAccountID,
+		"state":   imageStateActive,
+	} {
+		if state.Attributes[k] != v {
+			t.Errorf("expected %s to be %q, got %q", k, v, state.Attributes[k])
+		}
+	}
+
+	if _, err := testFakeApply(r, nil, map[string]interface{}{
+		"source_image_id": fakeImageBase64LTSID,
+	}, meta); err == nil {
+		t.Fatal("expected cloning an image that is not shared with the account to fail")
+	}
+
+	if err := testFakeDestroy(r, state, meta); err != nil {
+		t.Fatalf("error destroying cloned image: %s", err)
+	}
+	if _, found := f.images[state.ID]; found {
+		t.Fatal("expected cloned image to be deleted")
+	}
+	if _, found := f.images[fakeImageSharedID]; !found {
+		t.Fatal("expected source image to be left alone")
+	}
+}
@@ -0,0 +1,107 @@
+package triton
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/TritonDataCenter/triton-go/compute"
+	"github.com/TritonDataCenter/triton-go/errors"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceImageExport() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceImageExportCreate,
+		Read:   resourceImageExportRead,
+		Delete: resourceImageExportDelete,
+
+		Schema: map[string]*schema.Schema{
+			"image_id": {
+				Description: "The ID of the image to export.",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+
+			"manta_path": {
+				Description: "The Manta path to export the image to. A directory path, ending in a slash, receives files named after the image name and version.",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+
+			// Computed parameters
+			"manta_url": {
+				Description: "The URL of the Manta service the image was exported to.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"image_path": {
+				Description: "The Manta path of the exported image file.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"manifest_path": {
+				Description: "The Manta path of the exported image manifest.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceImageExportCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	c, err := client.Compute()
+	if err != nil {
+		return err
+	}
+
+	imageID := d.Get("image_id").(string)
+	mantaPath := d.Get("manta_path").(string)
+
+	log.Printf("[DEBUG] Exporting image %q to %q", imageID, mantaPath)
+	location, err := exportImage(context.Background(), c, imageID, mantaPath)
+	if err != nil {
+		return err
+	}
+
+	d.SetId(location.ManifestPath)
+	d.Set("manta_url", location.MantaURL)
+	d.Set("image_path", location.ImagePath)
+	d.Set("manifest_path", location.ManifestPath)
+
+	return resourceImageExportRead(d, meta)
+}
+
+func resourceImageExportRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	c, err := client.Compute()
+	if err != nil {
+		return err
+	}
+
+	// The exported files live in Manta, outside of CloudAPI, so the export
+	// is only tracked for as long as its source image exists.
+	_, err = c.Images().Get(context.Background(), &compute.GetImageInput{
+		ImageID: d.Get("image_id").(string),
+	})
+	if err != nil {
+		if errors.IsSpecificStatusCode(err, http.StatusNotFound) || errors.IsSpecificStatusCode(err, http.StatusGone) {
+			log.Printf("[DEBUG] Image %q not found or has been deleted", d.Get("image_id"))
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	return nil
+}
+
+func resourceImageExportDelete(d *schema.ResourceData, meta interface{}) error {
+	// The exported files are left in Manta; they are not owned by CloudAPI.
+	d.SetId("")
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package triton
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccTritonImageExport_basic(t *testing.T) {
+	imageName := fmt.Sprintf("acctest-%d", acctest.RandInt())
+	mantaPath := testAccConfig(t, "export_manta_path")
+	if mantaPath == "" {
+		t.Skip("testacc_export_manta_path must be set to test image exports")
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckTritonImageDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTritonImageExport_basic(t, imageName, mantaPath),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("triton_image_export.test", "manta_url"),
+					resource.TestCheckResourceAttrSet("triton_image_export.test", "image_path"),
+					resource.TestCheckResourceAttrSet("triton_image_export.test", "manifest_path"),
+				),
+			},
+		},
+	})
+}
+
+var testAccTritonImageExport_basic = func(t *testing.T, imageName string, mantaPath string) string {
+	return testAccTritonImage_basic(t, imageName, "exported") + fmt.Sprintf(`
+		resource "triton_image_export" "test" {
+			image_id = triton_image.test.id
+			manta_path = "%s"
+		}
+	`, mantaPath)
+}
+
+func TestFakeTritonImageExport_basic(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+
+	clone, err := testFakeApply(resourceImageClone(), nil, map[string]interface{}{
+		"source_image_id": fakeImageSharedID,
+	}, meta)
+	if err != nil {
+		t.Fatalf("error cloning image: %s", err)
+	}
+
+	r := resourceImageExport()
+	state, err := testFakeApply(r, nil, map[string]interface{}{
+		"image_id":   clone.ID,
+		"manta_path": "/fake-account/stor/images/",
+	}, meta)
+	if err != nil {
+		t.Fatalf("error exporting image: %s", err)
+	}
+	for k, v := range map[string]string{
+		"manta_url":     "https://manta.example.com",
+		"image_path":    "/fake-account/stor/images/partner-base-2.1.0.zfs.gz",
+		"manifest_path": "/fake-account/stor/images/partner-base-2.1.0.imgmanifest",
+	} {
+		if state.Attributes[k] != v {
+			t.Errorf("expected %s to be %q, got %q", k, v, state.Attributes[k])
+		}
+	}
+
+	if _, err := testFakeApply(r, nil, map[string]interface{}{
+		"image_id":   fakeImageSharedID,
+		"manta_path": "/fake-account/stor/images/",
+	}, meta); err == nil || !strings.Contains(err.Error(), "NotAuthorized") {
+		t.Fatalf("expected exporting an image owned by another account to fail, got %v", err)
+	}
+
+	if err := testFakeDestroy(resourceImageClone(), clone, meta); err != nil {
+		t.Fatal(err)
+	}
+	state, err = testFakeRefresh(r, state, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != nil && state.ID != "" {
+		t.Fatal("expected export to be removed from state with its image")
+	}
+}
@@ -0,0 +1,127 @@
+package triton
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/TritonDataCenter/triton-go/compute"
+	"github.com/TritonDataCenter/triton-go/errors"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceImageShare() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceImageShareCreate,
+		Read:   resourceImageShareRead,
+		Delete: resourceImageShareDelete,
+		Importer: &schema.ResourceImporter{
+			State: func(d *schema.ResourceData, meta any) ([]*schema.ResourceData, error) {
+				imageID, accountID, err := resourceImageShareParseIds(d.Id())
+				if err != nil {
+					return nil, err
+				}
+
+				d.Set("image_id", imageID)
+				d.Set("account_id", accountID)
+
+				return []*schema.ResourceData{d}, nil
+			},
+		},
+
+		Schema: map[string]*schema.Schema{
+			"image_id": {
+				Description: "The ID of the private image to share.",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+
+			"account_id": {
+				Description: "The UUID of the account to share the image with.",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+		},
+	}
+}
+
+func resourceImageShareCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	c, err := client.Compute()
+	if err != nil {
+		return err
+	}
+
+	imageID := d.Get("image_id").(string)
+	accountID := d.Get("account_id").(string)
+
+	log.Printf("[DEBUG] Sharing image %q with account %q", imageID, accountID)
+	if _, err := shareImage(context.Background(), c, imageID, accountID); err != nil {
+		return err
+	}
+
+	d.SetId(fmt.Sprintf("%s.%s", imageID, accountID))
+
+	return resourceImageShareRead(d, meta)
+}
+
+func resourceImageShareRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	c, err := client.Compute()
+	if err != nil {
+		return err
+	}
+
+	image, err := c.Images().Get(context.Background(), &compute.GetImageInput{
+		ImageID: d.Get("image_id").(string),
+	})
+	if err != nil {
+		if errors.IsSpecificStatusCode(err, http.StatusNotFound) || errors.IsSpecificStatusCode(err, http.StatusGone) {
+			log.Printf("[DEBUG] Image %q not found or has been deleted", d.Get("image_id"))
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	accountID := d.Get("account_id").(string)
+	for _, account := range image.ACL {
+		if account == accountID {
+			return nil
+		}
+	}
+
+	log.Printf("[DEBUG] Image %q is no longer shared with account %q", image.ID, accountID)
+	d.SetId("")
+
+	return nil
+}
+
+func resourceImageShareDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	c, err := client.Compute()
+	if err != nil {
+		return err
+	}
+
+	_, err = unshareImage(context.Background(), c, d.Get("image_id").(string), d.Get("account_id").(string))
+	if err != nil && !errors.IsSpecificStatusCode(err, http.StatusNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+func resourceImageShareParseIds(id string) (string, string, error) {
+	parts := strings.SplitN(id, ".", 2)
+
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("unexpected format of ID (%s), expected imageId.accountId", id)
+	}
+
+	return parts[0], parts[1], nil
+}
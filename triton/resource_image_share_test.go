@@ -0,0 +1,173 @@
+package triton
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/TritonDataCenter/triton-go/compute"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccTritonImageShare_basic(t *testing.T) {
+	imageName := fmt.Sprintf("acctest-%d", acctest.RandInt())
+	accountID := testAccConfig(t, "share_account_id")
+	if accountID == "" {
+		t.Skip("testacc_share_account_id must be set to test image sharing")
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckTritonImageDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTritonImageShare_basic(t, imageName, accountID),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckTritonImageShared("triton_image_share.test", true),
+				),
+			},
+			{
+				ResourceName:      "triton_image_share.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config: testAccTritonImage_basic(t, imageName, "unshared"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("triton_image.test", "acl.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func testCheckTritonImageShared(name string, shared bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+		conn := testAccProvider.Meta().(*Client)
+		c, err := conn.Compute()
+		if err != nil {
+			return err
+		}
+
+		image, err := c.Images().Get(context.Background(), &compute.GetImageInput{
+			ImageID: rs.Primary.Attributes["image_id"],
+		})
+		if err != nil {
+			return fmt.Errorf("Bad: Check Image Share: %s", err)
+		}
+
+		found := false
+		for _, account := range image.ACL {
+			found = found || account == rs.Primary.Attributes["account_id"]
+		}
+		if found != shared {
+			return fmt.Errorf("Bad: Image %q shared with %q is %t, expected %t", image.ID, rs.Primary.Attributes["account_id"], found, shared)
+		}
+
+		return nil
+	}
+}
+
+var testAccTritonImageShare_basic = func(t *testing.T, imageName string, accountID string) string {
+	return testAccTritonImage_basic(t, imageName, "shared") + fmt.Sprintf(`
+		resource "triton_image_share" "test" {
+			image_id = triton_image.test.id
+			account_id = "%s"
+		}
+	`, accountID)
+}
+
+func TestFakeTritonImageShare_basic(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+
+	machine, err := testFakeApply(resourceMachine(), nil, map[string]interface{}{
+		"package":       "g1.nano",
+		"image":         fakeImageBase64LTSID,
+		"desired_state": "stopped",
+	}, meta)
+	if err != nil {
+		t.Fatalf("error creating machine: %s", err)
+	}
+	image, err := testFakeApply(resourceImage(), nil, map[string]interface{}{
+		"machine_id": machine.ID,
+		"name":       "golden",
+		"version":    "1.0.0",
+	}, meta)
+	if err != nil {
+		t.Fatalf("error creating image: %s", err)
+	}
+
+	r := resourceImageShare()
+	config := map[string]interface{}{
+		"image_id":   image.ID,
+		"account_id": fakePartnerAccountID,
+	}
+
+	state, err := testFakeApply(r, nil, config, meta)
+	if err != nil {
+		t.Fatalf("error sharing image: %s", err)
+	}
+	if state.ID != fmt.Sprintf("%s.%s", image.ID, fakePartnerAccountID) {
+		t.Fatalf("unexpected share ID %q", state.ID)
+	}
+	if acl := f.images[image.ID].ACL; len(acl) != 1 || acl[0] != fakePartnerAccountID {
+		t.Fatalf("expected image to be shared with %s, got %v", fakePartnerAccountID, acl)
+	}
+
+	// The image ACL, now managed by triton_image_share, must not produce a
+	// diff on the image itself.
+	image, err = testFakeRefresh(resourceImage(), image, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff, err := testFakePlan(resourceImage(), image, map[string]interface{}{
+		"machine_id": machine.ID,
+		"name":       "golden",
+		"version":    "1.0.0",
+	}, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected no diff on the shared image, got %#v", diff)
+	}
+
+	if err := testFakeDestroy(r, state, meta); err != nil {
+		t.Fatalf("error unsharing image: %s", err)
+	}
+	if acl := f.images[image.ID].ACL; len(acl) != 0 {
+		t.Fatalf("expected image to be unshared, got %v", acl)
+	}
+
+	state, err = testFakeRefresh(r, state, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != nil && state.ID != "" {
+		t.Fatal("expected share to be removed from state once the account is no longer in the ACL")
+	}
+}
+
+func TestResourceImageShareParseIds(t *testing.T) {
+	imageID, accountID, err := resourceImageShareParseIds(fmt.Sprintf("%s.%s", fakeImageUbuntuID, fakePartnerAccountID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imageID != fakeImageUbuntuID || accountID != fakePartnerAccountID {
+		t.Fatalf("unexpected IDs %q and %q", imageID, accountID)
+	}
+
+	for _, id := range []string{"", fakeImageUbuntuID, "." + fakePartnerAccountID, fakeImageUbuntuID + "."} {
+		if _, _, err := resourceImageShareParseIds(id); err == nil {
+			t.Errorf("expected %q to be rejected", id)
+		}
+	}
+}
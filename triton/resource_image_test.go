@@ -0,0 +1,268 @@
+package triton
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/TritonDataCenter/triton-go/compute"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func init() {
+	resource.AddTestSweepers("triton_image", &resource.Sweeper{
+		Name: "triton_image",
+		F:    testSweepImages,
+	})
+}
+
+func testSweepImages(region string) error {
+	meta, err := sharedConfigForRegion(region)
+	if err != nil {
+		return err
+	}
+
+	client := meta.(*Client)
+	c, err := client.Compute()
+	if err != nil {
+		return err
+	}
+
+	images, err := c.Images().List(context.Background(), &compute.ListImagesInput{
+		State: "all",
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("[DEBUG] Found %d images", len(images))
+
+	for _, v := range images {
+		if strings.HasPrefix(v.Name, "acctest-") {
+			log.Printf("Destroying image %s@%s", v.Name, v.Version)
+
+			if err := c.Images().Delete(context.Background(), &compute.DeleteImageInput{
+				ImageID: v.ID,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func TestAccTritonImage_basic(t *testing.T) {
+	imageName := fmt.Sprintf("acctest-%d", acctest.RandInt())
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckTritonImageDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTritonImage_basic(t, imageName, "first"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckTritonImageExists("triton_image.test"),
+					resource.TestCheckResourceAttr("triton_image.test", "state", "active"),
+					resource.TestCheckResourceAttr("triton_image.test", "description", "first"),
+					resource.TestCheckResourceAttr("triton_image.test", "tags.role", "acctest"),
+				),
+			},
+			{
+				Config: testAccTritonImage_basic(t, imageName, "second"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckTritonImageExists("triton_image.test"),
+					resource.TestCheckResourceAttr("triton_image.test", "description", "second"),
+				),
+			},
+			{
+				ResourceName:            "triton_image.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"machine_id"},
+			},
+		},
+	})
+}
+
+func testCheckTritonImageExists(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+		conn := testAccProvider.Meta().(*Client)
+		c, err := conn.Compute()
+		if err != nil {
+			return err
+		}
+
+		exists, err := resourceExists(c.Images().Get(context.Background(), &compute.GetImageInput{
+			ImageID: rs.Primary.ID,
+		}))
+		if err != nil {
+			return fmt.Errorf("Bad: Check Image Exists: %s", err)
+		}
+
+		if !exists {
+			return fmt.Errorf("Bad: Image %q does not exist", rs.Primary.ID)
+		}
+
+		return nil
+	}
+}
+
+func testCheckTritonImageDestroy(s *terraform.State) error {
+	conn := testAccProvider.Meta().(*Client)
+	c, err := conn.Compute()
+	if err != nil {
+		return err
+	}
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "triton_image" {
+			continue
+		}
+
+		exists, err := resourceExists(c.Images().Get(context.Background(), &compute.GetImageInput{
+			ImageID: rs.Primary.ID,
+		}))
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			return fmt.Errorf("Bad: Image %q still exists", rs.Primary.ID)
+		}
+	}
+
+	return nil
+}
+
+var testAccTritonImage_basic = func(t *testing.T, imageName string, description string) string {
+	return testAccTritonMachine_singleMachine(t, imageName, `desired_state = "stopped"`) + fmt.Sprintf(`
+		resource "triton_image" "test" {
+			machine_id = triton_machine.test.id
+			name = "%s"
+			version = "1.0.0"
+			description = "%s"
+
+			tags = {
+				role = "acctest"
+			}
+		}
+	`, imageName, description)
+}
+
+func TestFakeTritonImage_basic(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+
+	machine, err := testFakeApply(resourceMachine(), nil, map[string]interface{}{
+		"package":       "g1.nano",
+		"image":         fakeImageBase64LTSID,
+		"desired_state": "stopped",
+	}, meta)
+	if err != nil {
+		t.Fatalf("error creating machine: %s", err)
+	}
+
+	r := resourceImage()
+	config := map[string]interface{}{
+		"machine_id":  machine.ID,
+		"name":        "golden",
+		"version":     "1.0.0",
+		"description": "Golden image",
+		"homepage":    "https://example.com",
+		"tags": map[string]interface{}{
+			"role": "db",
+		},
+	}
+
+	state, err := testFakeApply(r, nil, config, meta)
+	if err != nil {
+		t.Fatalf("error creating image: %s", err)
+	}
+	for k, v := range map[string]string{
+		"state":     imageStateActive,
+		"os":        "smartos",
+		"type":      "zone-dataset",
+		"owner":     fakeAccountID,
+		"tags.role": "db",
+		"homepage":  "https://example.com",
+	} {
+		if state.Attributes[k] != v {
+			t.Errorf("expected %s to be %q, got %q", k, v, state.Attributes[k])
+		}
+	}
+
+	delete(config, "homepage")
+	config["description"] = "Golden image, updated"
+	config["acl"] = []interface{}{fakePartnerAccountID}
+	state, err = testFakeApply(r, state, config, meta)
+	if err != nil {
+		t.Fatalf("error updating image: %s", err)
+	}
+	image := f.images[state.ID]
+	if image.Description != "Golden image, updated" || image.Homepage != "" || len(image.ACL) != 1 {
+		t.Fatalf("image was not updated in place: %#v", image)
+	}
+
+	diff, err := testFakePlan(r, state, config, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected an empty plan after update, got %#v", diff)
+	}
+
+	// Leaving acl out leaves the ACL alone, setting it empty clears it.
+	delete(config, "acl")
+	diff, err = testFakePlan(r, state, config, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected leaving acl out to keep the ACL, got %#v", diff)
+	}
+	config["acl"] = []interface{}{}
+	state, err = testFakeApply(r, state, config, meta)
+	if err != nil {
+		t.Fatalf("error clearing image ACL: %s", err)
+	}
+	if acl := f.images[state.ID].ACL; len(acl) != 0 {
+		t.Fatalf("expected the ACL to be cleared, got %v", acl)
+	}
+
+	// An imported image does not know its machine, which must not
+	// replace it.
+	imported, err := r.Importer.State(r.Data(&terraform.InstanceState{ID: state.ID}), meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	importedState, err := testFakeRefresh(r, imported[0].State(), meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if importedState.Attributes["name"] != "golden" || importedState.Attributes["machine_id"] != "" {
+		t.Fatalf("unexpected imported image: %v", importedState.Attributes)
+	}
+	diff, err = testFakePlan(r, importedState, config, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected an empty plan after import, got %#v", diff)
+	}
+
+	if err := testFakeDestroy(r, state, meta); err != nil {
+		t.Fatalf("error destroying image: %s", err)
+	}
+	if _, found := f.images[state.ID]; found {
+		t.Fatal("expected image to be deleted")
+	}
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/TritonDataCenter/triton-go/compute"
 	"github.com/TritonDataCenter/triton-go/errors"
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/mitchellh/hashstructure"
@@ -46,6 +47,9 @@ func resourceMachine() *schema.Resource {
 		Update:   resourceMachineUpdate,
 		Delete:   resourceMachineDelete,
 		Timeouts: slowResourceTimeout,
+
+		CustomizeDiff: resourceMachineCustomizeDiff,
+
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
@@ -125,19 +129,56 @@ func resourceMachine() *schema.Resource {
 			},
 
 			"networks": {
-				Description: "Desired network IDs",
-				Type:        schema.TypeSet,
-				Optional:    true,
+				Description:   "Desired network IDs",
+				Type:          schema.TypeSet,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"nic", "network_interface"},
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
 			},
 
+			"network_interface": {
+				Description:   "Ordered list of network interfaces, the first of which is the primary one unless another is marked primary",
+				Type:          schema.TypeList,
+				Optional:      true,
+				ConflictsWith: []string{"networks", "nic"},
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"network": {
+							Description: "ID of the network to attach the interface to",
+							Required:    true,
+							Type:        schema.TypeString,
+						},
+						"ip": {
+							Description:  "IPv4 address of the interface",
+							Optional:     true,
+							Computed:     true,
+							Type:         schema.TypeString,
+							ValidateFunc: validateIPv4Address,
+						},
+						"primary": {
+							Description: "Whether this is the machine's primary interface",
+							Optional:    true,
+							Computed:    true,
+							Type:        schema.TypeBool,
+						},
+						"mac": {
+							Description: "MAC address of the interface",
+							Computed:    true,
+							Type:        schema.TypeString,
+						},
+					},
+				},
+			},
+
 			"nic": {
-				Description: "Network interface",
-				Type:        schema.TypeSet,
-				Computed:    true,
-				Optional:    true,
+				Description:   "Network interface",
+				Type:          schema.TypeSet,
+				Computed:      true,
+				Optional:      true,
+				ConflictsWith: []string{"networks", "network_interface"},
 				Set: func(v interface{}) int {
 					m := v.(map[string]interface{})
 					return hashcodeString(m["network"].(string))
@@ -145,9 +186,11 @@ func resourceMachine() *schema.Resource {
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"ip": {
-							Description: "NIC's IPv4 address",
-							Computed:    true,
-							Type:        schema.TypeString,
+							Description:  "NIC's IPv4 address",
+							Optional:     true,
+							Computed:     true,
+							Type:         schema.TypeString,
+							ValidateFunc: validateIPv4Address,
 						},
 						"mac": {
 							Description: "NIC's MAC address",
@@ -156,6 +199,7 @@ func resourceMachine() *schema.Resource {
 						},
 						"primary": {
 							Description: "Whether this is the machine's primary NIC",
+							Optional:    true,
 							Computed:    true,
 							Type:        schema.TypeBool,
 						},
@@ -200,6 +244,19 @@ func resourceMachine() *schema.Resource {
 				Optional:    true,
 				Default:     false,
 			},
+			"desired_state": {
+				Description:  "Desired run state of the machine (running or stopped)",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      machineStateRunning,
+				ValidateFunc: resourceMachineValidateDesiredState,
+			},
+			"reboot_trigger": {
+				Description: "Arbitrary map of values that, when changed, will reboot the machine",
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
 
 			"volume": {
 				Description: "Volume to attach to the machine",
@@ -361,6 +418,21 @@ func resourceMachineCreate(d *schema.ResourceData, meta interface{}) error {
 		networks = append(networks, network.(string))
 	}
 
+	// CloudAPI makes the first network the primary one unless told
+	// otherwise, so the primary NIC goes first.
+	attachments := expandMachineNetworks(d.Get("nic").(*schema.Set).List())
+	if interfaces := machineNetworkInterfaces(d); len(interfaces) > 0 {
+		attachments = interfaces
+	}
+	var networkObjects []compute.NetworkObject
+	for _, attachment := range attachments {
+		if attachment.Primary {
+			networkObjects = append([]compute.NetworkObject{attachment.networkObject()}, networkObjects...)
+		} else {
+			networkObjects = append(networkObjects, attachment.networkObject())
+		}
+	}
+
 	metadata := map[string]string{}
 	for k, v := range d.Get("metadata").(map[string]interface{}) {
 		metadata[k] = v.(string)
@@ -439,6 +511,7 @@ func resourceMachineCreate(d *schema.ResourceData, meta interface{}) error {
 		Package:         d.Get("package").(string),
 		Image:           d.Get("image").(string),
 		Networks:        networks,
+		NetworkObjects:  networkObjects,
 		Metadata:        metadata,
 		Affinity:        affinity,
 		Tags:            tags,
@@ -571,6 +644,20 @@ func resourceMachineRead(d *schema.ResourceData, meta interface{}) error {
 	d.Set("deletion_protection_enabled", machine.DeletionProtection)
 	d.Set("delegate_dataset", machine.DelegateDataset)
 
+	// Only settled states are reflected back into desired_state so that a
+	// machine stopped or started outside of Terraform shows up as drift.
+	switch machine.State {
+	case machineStateRunning, machineStateStopped:
+		d.Set("desired_state", machine.State)
+	}
+
+	// NICs allocated from a network pool are reported on one of its member
+	// networks; keep the pool ID in `networks` so that it does not diff.
+	nicNetworks, err := resolveNetworkPools(client, machineConfiguredNetworks(d), nics)
+	if err != nil {
+		return err
+	}
+
 	// create and update NICs
 	var (
 		machineNICs []map[string]interface{}
@@ -586,14 +673,35 @@ func resourceMachineRead(d *schema.ResourceData, meta interface{}) error {
 				"netmask": nic.Netmask,
 				"gateway": nic.Gateway,
 				"state":   nic.State,
-				"network": nic.Network,
+				"network": nicNetworks[nic.MAC],
 			},
 		)
-		networks = append(networks, nic.Network)
+		networks = append(networks, nicNetworks[nic.MAC])
 	}
 	d.Set("nic", machineNICs)
 	d.Set("networks", networks)
 
+	// The interfaces keep the order they are configured in, followed by any
+	// NIC added outside of Terraform.
+	if interfaces := machineNetworkInterfaces(d); len(interfaces) > 0 {
+		matched := matchMachineNICs(interfaces, nics, nicNetworks)
+		claimed := map[string]bool{}
+		var networkInterfaces []map[string]interface{}
+		for _, nic := range append(matched, nics...) {
+			if nic == nil || claimed[nic.MAC] {
+				continue
+			}
+			claimed[nic.MAC] = true
+			networkInterfaces = append(networkInterfaces, map[string]interface{}{
+				"network": nicNetworks[nic.MAC],
+				"ip":      nic.IP,
+				"primary": nic.Primary,
+				"mac":     nic.MAC,
+			})
+		}
+		d.Set("network_interface", networkInterfaces)
+	}
+
 	for argumentName, metadataKey := range metadataArgumentsToKeys {
 		d.Set(argumentName, machine.Metadata[metadataKey])
 		delete(machine.Metadata, metadataKey)
@@ -738,6 +846,13 @@ func resourceMachineUpdate(d *schema.ResourceData, meta interface{}) error {
 	if d.HasChange("package") && !d.IsNewResource() {
 		newPackage := d.Get("package").(string)
 
+		// A resize leaves the machine in the run state it was in, which is
+		// the last state we observed rather than the one being requested.
+		currentState := d.Get("state").(string)
+		if currentState == "" {
+			currentState = machineStateRunning
+		}
+
 		err := c.Instances().Resize(context.Background(), &compute.ResizeInstanceInput{
 			ID:      d.Id(),
 			Package: newPackage,
@@ -747,7 +862,7 @@ func resourceMachineUpdate(d *schema.ResourceData, meta interface{}) error {
 		}
 
 		stateConf := &retry.StateChangeConf{
-			Target: []string{fmt.Sprintf("%s@%s", newPackage, "running")},
+			Target: []string{fmt.Sprintf("%s@%s", newPackage, currentState)},
 			Refresh: func() (interface{}, string, error) {
 				inst, err := c.Instances().Get(context.Background(), &compute.GetInstanceInput{
 					ID: d.Id(),
@@ -805,8 +920,7 @@ func resourceMachineUpdate(d *schema.ResourceData, meta interface{}) error {
 		}
 	}
 
-	if d.HasChange("networks") && !d.IsNewResource() {
-
+	if (d.HasChange("networks") || d.HasChange("nic") || d.HasChange("network_interface")) && !d.IsNewResource() {
 		nics, err := c.Instances().ListNICs(context.Background(), &compute.ListNICsInput{
 			InstanceID: d.Id(),
 		})
@@ -814,61 +928,68 @@ func resourceMachineUpdate(d *schema.ResourceData, meta interface{}) error {
 			return err
 		}
 
-		oRaw, nRaw := d.GetChange("networks")
-		o := oRaw.(*schema.Set).List()
-		n := nRaw.(*schema.Set).List()
+		// Only one of `networks`, `nic` and `network_interface` is
+		// configured; the others are computed and do not change. Changes to
+		// the IP or primary flag of a `nic` are only known from the
+		// configuration, see resourceMachineCustomizeDiff.
+		var o, n []machineNetwork
+		switch {
+		case d.HasChange("network_interface"):
+			oRaw, _ := d.GetChange("network_interface")
+			o = expandMachineNetworks(oRaw.([]interface{}))
+			n = machineNetworkInterfaces(d)
+		case d.HasChange("nic"):
+			oRaw, _ := d.GetChange("nic")
+			o = expandMachineNetworks(oRaw.(*schema.Set).List())
+			n = machineConfiguredNICs(d.GetRawConfig(), "nic")
+			if n == nil {
+				n = expandMachineNetworks(d.Get("nic").(*schema.Set).List())
+			}
+		default:
+			oRaw, nRaw := d.GetChange("networks")
+			o = expandMachineNetworks(oRaw.(*schema.Set).List())
+			n = expandMachineNetworks(nRaw.(*schema.Set).List())
+		}
 
-		networksToRemove := differenceNetworks(o, n)
-		for _, toRemove := range networksToRemove {
-			var macId string
-			for _, nic := range nics {
-				if nic.Network == toRemove {
-					macId = nic.MAC
-					break
-				}
+		var networkIDs []interface{}
+		for _, attachment := range append(o, n...) {
+			networkIDs = append(networkIDs, attachment.Network)
+		}
+		nicNetworks, err := resolveNetworkPools(client, networkIDs, nics)
+		if err != nil {
+			return err
+		}
+
+		// New NICs are added before old NICs are removed, so that the
+		// machine is never left without the networks it is meant to keep.
+		// Nothing is changed when the plan cannot be applied as a whole.
+		plan := planMachineNICs(n, nics, nicNetworks)
+		if err := plan.validate(d.Id()); err != nil {
+			return err
+		}
+		for _, toAdd := range plan.add {
+			log.Printf("[DEBUG] Adding NIC with Network %s", toAdd.Network)
+			nic, err := addNIC(context.Background(), c, d.Id(), toAdd.networkObject(), toAdd.Primary)
+			if err != nil {
+				return err
 			}
 
-			if macId != "" {
-				log.Printf("[DEBUG] Removing NIC with MacId %s", macId)
-				err := c.Instances().RemoveNIC(context.Background(), &compute.RemoveNICInput{
-					InstanceID: d.Id(),
-					MAC:        macId,
-				})
-				if err != nil {
-					return err
-				}
+			if err := waitForNICState(c, d.Id(), nic.MAC); err != nil {
+				return err
 			}
 		}
 
-		networksToAdd := differenceNetworks(n, o)
-		for _, toAdd := range networksToAdd {
-			log.Printf("[DEBUG] Adding NIC with Network %s", toAdd)
-			nic, err := c.Instances().AddNIC(context.Background(), &compute.AddNICInput{
+		for _, macId := range plan.remove {
+			log.Printf("[DEBUG] Removing NIC with MacId %s", macId)
+			err := c.Instances().RemoveNIC(context.Background(), &compute.RemoveNICInput{
 				InstanceID: d.Id(),
-				Network:    toAdd,
+				MAC:        macId,
 			})
 			if err != nil {
 				return err
 			}
 
-			stateConf := &retry.StateChangeConf{
-				Target: []string{"running"},
-				Refresh: func() (interface{}, string, error) {
-					n, err := c.Instances().GetNIC(context.Background(), &compute.GetNICInput{
-						InstanceID: d.Id(),
-						MAC:        nic.MAC,
-					})
-					if err != nil {
-						return nil, "", err
-					}
-
-					return n, n.State, nil
-				},
-				Timeout:    machineStateChangeTimeout,
-				MinTimeout: 3 * time.Second,
-			}
-			_, err = stateConf.WaitForState()
-			if err != nil {
+			if err := waitForNICRemoval(c, d.Id(), macId); err != nil {
 				return err
 			}
 		}
@@ -917,6 +1038,63 @@ func resourceMachineUpdate(d *schema.ResourceData, meta interface{}) error {
 		}
 	}
 
+	if d.HasChange("desired_state") {
+		desiredState := d.Get("desired_state").(string)
+
+		inst, err := c.Instances().Get(context.Background(), &compute.GetInstanceInput{
+			ID: d.Id(),
+		})
+		if err != nil {
+			return err
+		}
+
+		if inst.State != desiredState {
+			switch desiredState {
+			case machineStateRunning:
+				log.Printf("[INFO] Starting machine %q", d.Id())
+				err = c.Instances().Start(context.Background(), &compute.StartInstanceInput{
+					InstanceID: d.Id(),
+				})
+			case machineStateStopped:
+				log.Printf("[INFO] Stopping machine %q", d.Id())
+				err = c.Instances().Stop(context.Background(), &compute.StopInstanceInput{
+					InstanceID: d.Id(),
+				})
+			}
+			if err != nil {
+				return err
+			}
+
+			if err := waitForMachineState(c, d.Id(), desiredState); err != nil {
+				return err
+			}
+		}
+	}
+
+	// A reboot is only meaningful for a machine that stays running; a machine
+	// that was just started or stopped above has already been power cycled.
+	if d.HasChange("reboot_trigger") && !d.IsNewResource() && !d.HasChange("desired_state") &&
+		d.Get("desired_state").(string) == machineStateRunning {
+		inst, err := c.Instances().Get(context.Background(), &compute.GetInstanceInput{
+			ID: d.Id(),
+		})
+		if err != nil {
+			return err
+		}
+
+		log.Printf("[INFO] Rebooting machine %q", d.Id())
+		err = c.Instances().Reboot(context.Background(), &compute.RebootInstanceInput{
+			InstanceID: d.Id(),
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := waitForMachineReboot(c, d.Id(), inst.Updated); err != nil {
+			return err
+		}
+	}
+
 	metadata := map[string]string{}
 	for k, v := range d.Get("metadata").(map[string]interface{}) {
 		metadata[k] = v.(string)
@@ -1042,6 +1220,94 @@ func resourceMachineValidateName(value interface{}, name string) (warnings []str
 	return warnings, errors
 }
 
+func resourceMachineValidateDesiredState(value interface{}, name string) (warnings []string, errors []error) {
+	switch value.(string) {
+	case machineStateRunning, machineStateStopped:
+	default:
+		errors = append(errors, fmt.Errorf(`%s must be one of %q or %q, got %q`, name, machineStateRunning, machineStateStopped, value.(string)))
+	}
+
+	return warnings, errors
+}
+
+// waitForMachineState waits for a machine to settle in the target state.
+func waitForMachineState(c *compute.ComputeClient, id string, target string) error {
+	stateConf := &retry.StateChangeConf{
+		Pending: []string{
+			machineStateProvisioning,
+			machineStateRunning,
+			machineStateStopping,
+			machineStateStopped,
+			"offline",
+		},
+		Target: []string{target},
+		Refresh: func() (interface{}, string, error) {
+			inst, err := c.Instances().Get(context.Background(), &compute.GetInstanceInput{
+				ID: id,
+			})
+			if err != nil {
+				return nil, "", err
+			}
+			if inst.State == machineStateFailed {
+				return nil, "", fmt.Errorf("instance %q entered the %q state", id, inst.State)
+			}
+
+			return inst, inst.State, nil
+		},
+		Timeout:    machineStateChangeTimeout,
+		MinTimeout: 3 * time.Second,
+	}
+	_, err := stateConf.WaitForState()
+
+	return err
+}
+
+// machineStateRebooted is the state waitForMachineReboot reports once a
+// machine has gone down and come back up.
+const machineStateRebooted = "rebooted"
+
+// waitForMachineReboot waits for a machine to go through a reboot. A reboot
+// leaves the machine in the state it started in, so the machine is only taken
+// to have rebooted once it has been seen in another state, or CloudAPI has
+// updated it since updated, and it is running again.
+func waitForMachineReboot(c *compute.ComputeClient, id string, updated time.Time) error {
+	wentDown := false
+	stateConf := &retry.StateChangeConf{
+		Pending: []string{
+			machineStateRunning,
+			machineStateStopping,
+			machineStateStopped,
+			"offline",
+		},
+		Target: []string{machineStateRebooted},
+		Refresh: func() (interface{}, string, error) {
+			inst, err := c.Instances().Get(context.Background(), &compute.GetInstanceInput{
+				ID: id,
+			})
+			if err != nil {
+				return nil, "", err
+			}
+			if inst.State == machineStateFailed {
+				return nil, "", fmt.Errorf("instance %q entered the %q state", id, inst.State)
+			}
+
+			if inst.State != machineStateRunning {
+				wentDown = true
+				return inst, inst.State, nil
+			}
+			if wentDown || inst.Updated.After(updated) {
+				return inst, machineStateRebooted, nil
+			}
+			return inst, inst.State, nil
+		},
+		Timeout:    machineStateChangeTimeout,
+		MinTimeout: 3 * time.Second,
+	}
+	_, err := stateConf.WaitForState()
+
+	return err
+}
+
 // castToTypeList casts an interface slice back into a proper slice of
 // strings. This handles pulling services out of various nested interface
 // collections that Terraform stores them under.
@@ -1125,18 +1391,429 @@ func hasValidDomainNames(d *schema.ResourceData, inst *compute.Instance) bool {
 	return true
 }
 
-func differenceNetworks(a, b []interface{}) []string {
-	mb := map[string]bool{}
-	for _, x := range b {
-		mb[x.(string)] = true
+// machineNetwork is an attachment of a machine to a network, as given by
+// either an entry of `networks` or a `nic` block.
+type machineNetwork struct {
+	Network string
+	IP      string
+	MAC     string
+	Primary bool
+}
+
+// expandMachineNetworks converts the entries of `networks` (network IDs) or
+// of `nic` and `network_interface` (maps) into machineNetworks.
+func expandMachineNetworks(raw []interface{}) []machineNetwork {
+	result := make([]machineNetwork, 0, len(raw))
+	for _, v := range raw {
+		switch v := v.(type) {
+		case string:
+			result = append(result, machineNetwork{Network: v})
+		case map[string]interface{}:
+			attachment := machineNetwork{Network: v["network"].(string)}
+			if ip, ok := v["ip"].(string); ok {
+				attachment.IP = ip
+			}
+			if mac, ok := v["mac"].(string); ok {
+				attachment.MAC = mac
+			}
+			if primary, ok := v["primary"].(bool); ok {
+				attachment.Primary = primary
+			}
+			result = append(result, attachment)
+		}
+	}
+	return result
+}
+
+// machineConfiguredNetworks returns the network IDs a machine is meant to be
+// attached to, whichever of `networks` and `nic` holds them.
+func machineConfiguredNetworks(d *schema.ResourceData) []interface{} {
+	var result []interface{}
+	for _, key := range []string{"networks", "nic"} {
+		for _, attachment := range expandMachineNetworks(d.Get(key).(*schema.Set).List()) {
+			result = append(result, attachment.Network)
+		}
+	}
+	for _, attachment := range machineNetworkInterfaces(d) {
+		result = append(result, attachment.Network)
+	}
+	return result
+}
+
+// machineNetworkInterfaces returns the `network_interface` entries of a
+// machine in order. The IP addresses and primary flags are computed, so when
+// the configuration is at hand they are taken from it rather than from the
+// plan, where they stick to list positions: the primary interface is the one
+// marked primary, otherwise the first one. MAC addresses are kept as a hint
+// for telling NICs on the same network apart.
+func machineNetworkInterfaces(d *schema.ResourceData) []machineNetwork {
+	interfaces := expandMachineNetworks(d.Get("network_interface").([]interface{}))
+	configured := machineConfiguredNICs(d.GetRawConfig(), "network_interface")
+	if configured == nil {
+		return interfaces
+	}
+	return mergeMachineNetworkInterfaces(configured, interfaces)
+}
+
+// mergeMachineNetworkInterfaces marks the primary one of the configured
+// interfaces and copies the MAC addresses of the interfaces at the same
+// positions in known onto them.
+func mergeMachineNetworkInterfaces(configured, known []machineNetwork) []machineNetwork {
+	primary := 0
+	for i, attachment := range configured {
+		if attachment.Primary {
+			primary = i
+		}
+	}
+	for i := range configured {
+		if i < len(known) {
+			configured[i].MAC = known[i].MAC
+		}
+		configured[i].Primary = i == primary
+	}
+	return configured
+}
+
+func (m machineNetwork) networkObject() compute.NetworkObject {
+	object := compute.NetworkObject{IPv4UUID: m.Network}
+	if m.IP != "" {
+		object.IPv4IPs = []string{m.IP}
+	}
+	return object
+}
+
+// matchesMachineNetwork reports whether two attachments refer to the same
+// NIC: they are on the same network, and have the same IP address unless one
+// of them leaves it to Triton.
+func matchesMachineNetwork(a, b machineNetwork) bool {
+	return a.Network == b.Network && (a.IP == "" || b.IP == "" || a.IP == b.IP)
+}
+
+// machineConfiguredNICs returns the `nic` or `network_interface` blocks of
+// the configuration, or nil if there are none or they are not known yet.
+func machineConfiguredNICs(config cty.Value, key string) []machineNetwork {
+	if config.IsNull() || !config.IsKnown() {
+		return nil
+	}
+	nics := config.GetAttr(key)
+	if nics.IsNull() || !nics.IsKnown() || nics.LengthInt() == 0 {
+		return nil
+	}
+
+	var result []machineNetwork
+	for it := nics.ElementIterator(); it.Next(); {
+		_, nic := it.Element()
+		network, ip, primary := nic.GetAttr("network"), nic.GetAttr("ip"), nic.GetAttr("primary")
+		if !network.IsKnown() || !ip.IsKnown() || !primary.IsKnown() {
+			return nil
+		}
+
+		attachment := machineNetwork{Network: network.AsString()}
+		if !ip.IsNull() {
+			attachment.IP = ip.AsString()
+		}
+		if !primary.IsNull() {
+			attachment.Primary = primary.True()
+		}
+		result = append(result, attachment)
+	}
+	return result
+}
+
+// resourceMachineCustomizeDiff plans an update of the NICs of a machine when
+// the IP address or primary flag of a configured `nic` does not match the
+// NIC on its network. The `nic` set is keyed on the network alone, so such
+// changes are not part of the diff otherwise.
+func resourceMachineCustomizeDiff(_ context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if err := customizeDiffNetworkInterfaces(d); err != nil {
+		return err
 	}
-	ab := []string{}
-	for _, x := range a {
-		if _, ok := mb[x.(string)]; !ok {
-			ab = append(ab, x.(string))
+	if err := customizeDiffPrimaryNIC(d); err != nil {
+		return err
+	}
+
+	// A stopped machine cannot be rebooted, and silently dropping the
+	// reboot would leave reboot_trigger claiming that it happened.
+	if d.Id() != "" && d.HasChange("reboot_trigger") && d.Get("desired_state").(string) == machineStateStopped {
+		return fmt.Errorf("reboot_trigger cannot reboot machine %q while desired_state is %q", d.Id(), machineStateStopped)
+	}
+
+	if d.Id() == "" {
+		return nil
+	}
+	desired := machineConfiguredNICs(d.GetRawConfig(), "nic")
+	if desired == nil {
+		return nil
+	}
+
+	oRaw, _ := d.GetChange("nic")
+	current := expandMachineNetworks(oRaw.(*schema.Set).List())
+
+	changed := false
+	for _, want := range desired {
+		found := false
+		for _, have := range current {
+			if matchesMachineNetwork(want, have) && (!want.Primary || have.Primary) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			changed = true
 		}
 	}
-	return ab
+
+	if changed && d.NewValueKnown("nic") {
+		return d.SetNewComputed("nic")
+	}
+
+	return nil
+}
+
+// customizeDiffNetworkInterfaces allows at most one `network_interface` to be
+// marked primary.
+func customizeDiffNetworkInterfaces(d *schema.ResourceDiff) error {
+	configured := machineConfiguredNICs(d.GetRawConfig(), "network_interface")
+
+	marked := 0
+	for _, attachment := range configured {
+		if attachment.Primary {
+			marked++
+		}
+	}
+	if marked > 1 {
+		return fmt.Errorf("only one network_interface can be marked primary, got %d", marked)
+	}
+
+	return nil
+}
+
+// customizeDiffPrimaryNIC rejects a `nic` or `network_interface`
+// configuration which makes an existing NIC of the machine its primary NIC,
+// so that the update does not fail after other NICs were already added.
+func customizeDiffPrimaryNIC(d *schema.ResourceDiff) error {
+	if d.Id() == "" {
+		return nil
+	}
+
+	desired := machineConfiguredNICs(d.GetRawConfig(), "network_interface")
+	if desired != nil {
+		oRaw, _ := d.GetChange("network_interface")
+		desired = mergeMachineNetworkInterfaces(desired, expandMachineNetworks(oRaw.([]interface{})))
+	} else if desired = machineConfiguredNICs(d.GetRawConfig(), "nic"); desired == nil {
+		return nil
+	}
+
+	oRaw, _ := d.GetChange("nic")
+	var nics []*compute.NIC
+	nicNetworks := map[string]string{}
+	for _, attachment := range expandMachineNetworks(oRaw.(*schema.Set).List()) {
+		nics = append(nics, &compute.NIC{
+			MAC:     attachment.MAC,
+			IP:      attachment.IP,
+			Network: attachment.Network,
+			Primary: attachment.Primary,
+		})
+		nicNetworks[attachment.MAC] = attachment.Network
+	}
+
+	return planMachineNICs(desired, nics, nicNetworks).validate(d.Id())
+}
+
+// machineNICPlan is the minimal set of changes which attaches a machine to
+// the desired networks: the NICs to add, the MAC of an existing NIC which is
+// meant to be primary, and the MACs of the NICs to remove.
+type machineNICPlan struct {
+	add     []machineNetwork
+	primary string
+	remove  []string
+}
+
+// validate returns an error if the plan makes an existing NIC the primary
+// NIC. CloudAPI can only make a NIC primary when it is added.
+func (p machineNICPlan) validate(machineID string) error {
+	if p.primary == "" {
+		return nil
+	}
+	return fmt.Errorf("cannot make the existing NIC %s the primary NIC of machine %q: CloudAPI can only make a NIC primary when it is added", p.primary, machineID)
+}
+
+// planMachineNICs works out how to get from the NICs of a machine to the
+// desired attachments. nicNetworks maps the MAC of each NIC to the network,
+// or network pool, it stands for.
+func planMachineNICs(desired []machineNetwork, nics []*compute.NIC, nicNetworks map[string]string) machineNICPlan {
+	var plan machineNICPlan
+
+	matched := matchMachineNICs(desired, nics, nicNetworks)
+	claimed := map[string]bool{}
+	for i, nic := range matched {
+		if nic == nil {
+			plan.add = append(plan.add, desired[i])
+			continue
+		}
+		claimed[nic.MAC] = true
+		if desired[i].Primary && !nic.Primary {
+			plan.primary = nic.MAC
+		}
+	}
+
+	for _, nic := range nics {
+		if !claimed[nic.MAC] {
+			plan.remove = append(plan.remove, nic.MAC)
+		}
+	}
+
+	return plan
+}
+
+// matchMachineNICs pairs each attachment with one of the NICs of a machine,
+// or nil if there is none. Each NIC is used at most once, so that several
+// NICs on the same network are told apart: first by IP address, then by MAC
+// address, then in the order the NICs are listed in.
+func matchMachineNICs(attachments []machineNetwork, nics []*compute.NIC, nicNetworks map[string]string) []*compute.NIC {
+	matched := make([]*compute.NIC, len(attachments))
+	claimed := map[string]bool{}
+
+	passes := []func(machineNetwork, *compute.NIC) bool{
+		func(a machineNetwork, nic *compute.NIC) bool {
+			return a.IP != "" && a.IP == nic.IP
+		},
+		func(a machineNetwork, nic *compute.NIC) bool {
+			return a.IP == "" && a.MAC != "" && a.MAC == nic.MAC
+		},
+		func(a machineNetwork, nic *compute.NIC) bool {
+			return a.IP == ""
+		},
+	}
+	for _, pass := range passes {
+		for i, attachment := range attachments {
+			if matched[i] != nil {
+				continue
+			}
+			for _, nic := range nics {
+				if claimed[nic.MAC] || nicNetworks[nic.MAC] != attachment.Network || !pass(attachment, nic) {
+					continue
+				}
+				matched[i] = nic
+				claimed[nic.MAC] = true
+				break
+			}
+		}
+	}
+
+	return matched
+}
+
+// waitForNICState waits for a NIC newly added to a machine to be running.
+func waitForNICState(c *compute.ComputeClient, machineID, mac string) error {
+	stateConf := &retry.StateChangeConf{
+		Target: []string{"running"},
+		Refresh: func() (interface{}, string, error) {
+			n, err := c.Instances().GetNIC(context.Background(), &compute.GetNICInput{
+				InstanceID: machineID,
+				MAC:        mac,
+			})
+			if err != nil {
+				return nil, "", err
+			}
+
+			return n, n.State, nil
+		},
+		Timeout:    machineStateChangeTimeout,
+		MinTimeout: 3 * time.Second,
+	}
+	_, err := stateConf.WaitForState()
+	return err
+}
+
+// waitForNICRemoval waits for a NIC removed from a machine to be gone.
+func waitForNICRemoval(c *compute.ComputeClient, machineID, mac string) error {
+	stateConf := &retry.StateChangeConf{
+		Target: []string{"removed"},
+		Refresh: func() (interface{}, string, error) {
+			n, err := c.Instances().GetNIC(context.Background(), &compute.GetNICInput{
+				InstanceID: machineID,
+				MAC:        mac,
+			})
+			if err != nil {
+				if errors.IsSpecificStatusCode(err, http.StatusNotFound) {
+					return mac, "removed", nil
+				}
+				return nil, "", err
+			}
+
+			return n, n.State, nil
+		},
+		Timeout:    machineStateChangeTimeout,
+		MinTimeout: 3 * time.Second,
+	}
+	_, err := stateConf.WaitForState()
+	return err
+}
+
+// resolveNetworkPools returns the network each NIC stands for in the given
+// list of configured networks, keyed by MAC address. CloudAPI reports the
+// network a NIC was actually allocated from, so a NIC created from a network
+// pool is mapped back to the pool when the pool is configured. Networks are
+// only listed when a configured network has no NIC directly attached to it.
+func resolveNetworkPools(client *Client, configured []interface{}, nics []*compute.NIC) (map[string]string, error) {
+	result := make(map[string]string, len(nics))
+	attached := map[string]bool{}
+	for _, nic := range nics {
+		result[nic.MAC] = nic.Network
+		attached[nic.Network] = true
+	}
+
+	var unmatched []string
+	for _, id := range configured {
+		if !attached[id.(string)] {
+			unmatched = append(unmatched, id.(string))
+		}
+	}
+	if len(unmatched) == 0 {
+		return result, nil
+	}
+
+	n, err := client.Network()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := listNetworks(context.Background(), n)
+	if err != nil {
+		return nil, err
+	}
+	pools := map[string]map[string]bool{}
+	for _, entry := range entries {
+		if entry.isPool() {
+			pools[entry.Id] = map[string]bool{}
+			for _, member := range entry.Networks {
+				pools[entry.Id][member] = true
+			}
+		}
+	}
+
+	configuredIDs := map[string]bool{}
+	for _, id := range configured {
+		configuredIDs[id.(string)] = true
+	}
+	for _, id := range unmatched {
+		members, found := pools[id]
+		if !found {
+			continue
+		}
+		for _, nic := range nics {
+			if result[nic.MAC] != nic.Network || configuredIDs[nic.Network] {
+				continue
+			}
+			if members[nic.Network] {
+				log.Printf("[DEBUG] NIC %s on network %s was allocated from network pool %s", nic.MAC, nic.Network, id)
+				result[nic.MAC] = id
+				break
+			}
+		}
+	}
+
+	return result, nil
 }
 
 // https://developer.hashicorp.com/terraform/plugin/sdkv2/guides/v2-upgrade-guide#removal-of-helper-hashcode-package
@@ -519,6 +519,40 @@ func TestAccTritonMachine_deletionProtection(t *testing.T) {
 	})
 }
 
+func TestAccTritonMachine_desiredState(t *testing.T) {
+	machineName := fmt.Sprintf("acctest-%d", acctest.RandInt())
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckTritonMachineDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTritonMachine_desiredState(t, machineName, `desired_state = "stopped"`),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckTritonMachineExists("triton_machine.test"),
+					resource.TestCheckResourceAttr("triton_machine.test", "state", "stopped"),
+				),
+			},
+			{
+				Config: testAccTritonMachine_desiredState(t, machineName, `desired_state = "running"`),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckTritonMachineExists("triton_machine.test"),
+					resource.TestCheckResourceAttr("triton_machine.test", "state", "running"),
+				),
+			},
+			{
+				Config: testAccTritonMachine_desiredState(t, machineName, `reboot_trigger = { serial = "1" }`),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckTritonMachineExists("triton_machine.test"),
+					resource.TestCheckResourceAttr("triton_machine.test", "state", "running"),
+					resource.TestCheckResourceAttr("triton_machine.test", "reboot_trigger.serial", "1"),
+				),
+			},
+		},
+	})
+}
+
 func TestAccTritonMachine_volume(t *testing.T) {
 	machineName := fmt.Sprintf("acctest-%d", acctest.RandInt())
 	// Note that we cannot use a randomized volume name, as that will change
@@ -589,6 +623,7 @@ var testAccTritonMachine_singleMachine = func(t *testing.T, machineName string,
 var testAccTritonMachine_deletionProtection = testAccTritonMachine_singleMachine
 var testAccTritonMachine_firewall = testAccTritonMachine_singleMachine
 var testAccTritonMachine_cns = testAccTritonMachine_singleMachine
+var testAccTritonMachine_desiredState = testAccTritonMachine_singleMachine
 
 // a "Basic" is just a singleMachine with no additional config.
 var testAccTritonMachine_basic = func(t *testing.T, machineName string) string {
@@ -846,3 +881,489 @@ var testAccTritonMachine_volume = func(t *testing.T, machineName string, volumeN
 		}
 	`, volumeName, machineName, packageName))
 }
+
+func TestFakeTritonMachine_basic(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	config := f.ProviderConfig() + fmt.Sprintf(`
+		resource "triton_machine" "test" {
+			name = "fake-machine"
+			package = "g1.nano"
+			image = "%s"
+		}
+	`, fakeImageBase64LTSID)
+
+	resource.UnitTest(t, resource.TestCase{
+		PreCheck:     func() { testFakePreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckTritonMachineDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckTritonMachineExists("triton_machine.test"),
+					resource.TestCheckResourceAttr("triton_machine.test", "state", machineStateRunning),
+					resource.TestCheckResourceAttr("triton_machine.test", "nic.#", "1"),
+					resource.TestCheckResourceAttrSet("triton_machine.test", "primaryip"),
+				),
+			},
+			{
+				ResourceName:      "triton_machine.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestFakeTritonMachine_lifecycle(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+	r := resourceMachine()
+
+	config := map[string]interface{}{
+		"name":     "fake-machine",
+		"package":  "g1.nano",
+		"image":    fakeImageBase64LTSID,
+		"networks": []interface{}{fakeFabricNetworkID},
+		"tags": map[string]interface{}{
+			"role": "test",
+		},
+	}
+
+	state, err := testFakeApply(r, nil, config, meta)
+	if err != nil {
+		t.Fatalf("error creating machine: %s", err)
+	}
+	for k, v := range map[string]string{
+		"state":          machineStateRunning,
+		"package":        "g1.nano",
+		"memory":         "512",
+		"tags.role":      "test",
+		"nic.#":          "1",
+		"networks.#":     "1",
+		"primaryip":      "192.168.128.5",
+		"domain_names.#": "2",
+	} {
+		if state.Attributes[k] != v {
+			t.Errorf("expected %s to be %q, got %q", k, v, state.Attributes[k])
+		}
+	}
+
+	config["package"] = "g1.small"
+	config["networks"] = []interface{}{fakeFabricNetworkID, fakePublicNetworkID}
+	state, err = testFakeApply(r, state, config, meta)
+	if err != nil {
+		t.Fatalf("error updating machine: %s", err)
+	}
+	if state.Attributes["memory"] != "2048" {
+		t.Errorf("expected memory to be resized to 2048, got %q", state.Attributes["memory"])
+	}
+	if state.Attributes["nic.#"] != "2" {
+		t.Errorf("expected 2 NICs after update, got %q", state.Attributes["nic.#"])
+	}
+
+	diff, err := testFakePlan(r, state, config, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected an empty plan after apply, got %#v", diff)
+	}
+
+	if err := testFakeDestroy(r, state, meta); err != nil {
+		t.Fatalf("error destroying machine: %s", err)
+	}
+	state, err = testFakeRefresh(r, state, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != nil && state.ID != "" {
+		t.Fatalf("expected machine to be gone, got %q", state.ID)
+	}
+}
+
+func TestFakeTritonMachine_networkPool(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+	r := resourceMachine()
+
+	config := map[string]interface{}{
+		"name":     "fake-machine",
+		"package":  "g1.nano",
+		"image":    fakeImageBase64LTSID,
+		"networks": []interface{}{fakeFabricNetworkID, fakePublicPoolID},
+	}
+
+	state, err := testFakeApply(r, nil, config, meta)
+	if err != nil {
+		t.Fatalf("error creating machine: %s", err)
+	}
+	onPool := false
+	for _, nic := range f.machines[state.ID].nics {
+		onPool = onPool || nic.Network == fakePublicNetworkID
+	}
+	if !onPool {
+		t.Fatal("expected a NIC on the public network of the pool")
+	}
+
+	state, err = testFakeRefresh(r, state, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff, err := testFakePlan(r, state, config, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected an empty plan for a machine on a network pool, got %#v", diff)
+	}
+
+	config["networks"] = []interface{}{fakeFabricNetworkID}
+	state, err = testFakeApply(r, state, config, meta)
+	if err != nil {
+		t.Fatalf("error updating machine: %s", err)
+	}
+	if nics := f.machines[state.ID].nics; len(nics) != 1 || nics[0].Network != fakeFabricNetworkID {
+		t.Fatalf("expected the NIC allocated from the pool to be removed, got %v", nics)
+	}
+}
+
+func TestFakeTritonMachine_nicAddressing(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+	r := resourceMachine()
+
+	config := map[string]interface{}{
+		"name":    "fake-machine",
+		"package": "g1.nano",
+		"image":   fakeImageBase64LTSID,
+		"nic": []interface{}{
+			map[string]interface{}{
+				"network": fakeFabricNetworkID,
+				"ip":      "192.168.128.20",
+			},
+			map[string]interface{}{
+				"network": fakePrivateNetworkID,
+				"primary": true,
+			},
+		},
+	}
+
+	state, err := testFakeApply(r, nil, config, meta)
+	if err != nil {
+		t.Fatalf("error creating machine: %s", err)
+	}
+	nics := map[string]*compute.NIC{}
+	for _, nic := range f.machines[state.ID].nics {
+		nics[nic.Network] = nic
+	}
+	if nic := nics[fakeFabricNetworkID]; nic == nil || nic.IP != "192.168.128.20" || nic.Primary {
+		t.Fatalf("expected a secondary NIC with IP 192.168.128.20 on the fabric network, got %#v", nic)
+	}
+	if nic := nics[fakePrivateNetworkID]; nic == nil || !nic.Primary {
+		t.Fatalf("expected the primary NIC on the private network, got %#v", nic)
+	}
+
+	diff, err := testFakePlan(r, state, config, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected an empty plan after create, got %#v", diff)
+	}
+
+	config["nic"] = []interface{}{
+		map[string]interface{}{
+			"network": fakeFabricNetworkID,
+			"ip":      "192.168.128.30",
+		},
+		map[string]interface{}{
+			"network": fakePrivateNetworkID,
+		},
+		map[string]interface{}{
+			"network": fakePublicNetworkID,
+			"primary": true,
+		},
+	}
+	state, err = testFakeApply(r, state, config, meta)
+	if err != nil {
+		t.Fatalf("error updating machine: %s", err)
+	}
+	nics = map[string]*compute.NIC{}
+	for _, nic := range f.machines[state.ID].nics {
+		nics[nic.Network] = nic
+	}
+	if len(nics) != 3 {
+		t.Fatalf("expected 3 NICs, got %d", len(nics))
+	}
+	if nic := nics[fakeFabricNetworkID]; nic.IP != "192.168.128.30" {
+		t.Fatalf("expected the fabric NIC to move to 192.168.128.30, got %s", nic.IP)
+	}
+	if !nics[fakePublicNetworkID].Primary || nics[fakePrivateNetworkID].Primary {
+		t.Fatal("expected the new public NIC to be the primary NIC")
+	}
+
+	diff, err = testFakePlan(r, state, config, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected an empty plan after update, got %#v", diff)
+	}
+
+	// CloudAPI cannot make an existing NIC primary, so this is rejected
+	// before any NIC is added.
+	config["nic"].([]interface{})[1].(map[string]interface{})["primary"] = true
+	config["nic"].([]interface{})[2].(map[string]interface{})["primary"] = false
+	config["nic"].([]interface{})[0].(map[string]interface{})["ip"] = "192.168.128.40"
+	requests := len(f.requests)
+	_, err = testFakeApply(r, state, config, meta)
+	if err == nil || !strings.Contains(err.Error(), "can only make a NIC primary when it is added") {
+		t.Fatalf("expected switching to an existing primary NIC to be rejected, got %v", err)
+	}
+	for _, request := range f.requests[requests:] {
+		if !strings.HasPrefix(request, http.MethodGet+" ") {
+			t.Fatalf("expected no changes to the machine, got %s", request)
+		}
+	}
+	if len(f.machines[state.ID].nics) != 3 || !nics[fakePublicNetworkID].Primary {
+		t.Fatal("expected the NICs of the machine to be left as they are")
+	}
+}
+
+func TestFakeTritonMachine_networkInterfaces(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+	r := resourceMachine()
+
+	config := map[string]interface{}{
+		"name":    "fake-machine",
+		"package": "g1.nano",
+		"image":   fakeImageBase64LTSID,
+		"network_interface": []interface{}{
+			map[string]interface{}{
+				"network": fakeFabricNetworkID,
+				"ip":      "192.168.128.20",
+			},
+			map[string]interface{}{
+				"network": fakeFabricNetworkID,
+			},
+			map[string]interface{}{
+				"network": fakePrivateNetworkID,
+			},
+		},
+	}
+
+	interfaces := func(state *terraform.InstanceState) []map[string]interface{} {
+		var result []map[string]interface{}
+		for _, v := range r.Data(state).Get("network_interface").([]interface{}) {
+			result = append(result, v.(map[string]interface{}))
+		}
+		return result
+	}
+
+	state, err := testFakeApply(r, nil, config, meta)
+	if err != nil {
+		t.Fatalf("error creating machine: %s", err)
+	}
+	created := interfaces(state)
+	if len(created) != 3 || len(f.machines[state.ID].nics) != 3 {
+		t.Fatalf("expected 3 network interfaces, got %v", created)
+	}
+	if created[0]["ip"] != "192.168.128.20" || created[0]["primary"] != true {
+		t.Fatalf("expected the first interface to be the primary one with IP 192.168.128.20, got %v", created[0])
+	}
+	if created[1]["network"] != fakeFabricNetworkID || created[1]["ip"] == created[0]["ip"] || created[2]["network"] != fakePrivateNetworkID {
+		t.Fatalf("expected the interfaces in configured order, got %v", created)
+	}
+
+	diff, err := testFakePlan(r, state, config, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected an empty plan after create, got %#v", diff)
+	}
+
+	// Drop the second NIC on the fabric network and add a public one as the
+	// primary NIC, keeping the NICs which are still wanted.
+	config["network_interface"] = []interface{}{
+		map[string]interface{}{
+			"network": fakePrivateNetworkID,
+		},
+		map[string]interface{}{
+			"network": fakeFabricNetworkID,
+			"ip":      "192.168.128.20",
+		},
+		map[string]interface{}{
+			"network": fakePublicNetworkID,
+			"primary": true,
+		},
+	}
+	state, err = testFakeApply(r, state, config, meta)
+	if err != nil {
+		t.Fatalf("error updating machine: %s", err)
+	}
+	updated := interfaces(state)
+	if len(updated) != 3 || len(f.machines[state.ID].nics) != 3 {
+		t.Fatalf("expected 3 network interfaces, got %v", updated)
+	}
+	if updated[0]["mac"] != created[2]["mac"] || updated[0]["primary"] != false {
+		t.Fatalf("expected the private NIC to be kept, got %v", updated[0])
+	}
+	if updated[1]["mac"] != created[0]["mac"] || updated[1]["primary"] != false {
+		t.Fatalf("expected the fabric NIC with IP 192.168.128.20 to be kept, got %v", updated[1])
+	}
+	if updated[2]["network"] != fakePublicNetworkID || updated[2]["primary"] != true {
+		t.Fatalf("expected a new primary public NIC, got %v", updated[2])
+	}
+	for _, nic := range f.machines[state.ID].nics {
+		if nic.MAC == created[1]["mac"] {
+			t.Fatalf("expected the second fabric NIC to be removed")
+		}
+	}
+
+	diff, err = testFakePlan(r, state, config, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected an empty plan after update, got %#v", diff)
+	}
+
+	// Without a marked interface, the first one is the primary one, which
+	// CloudAPI cannot make of an existing NIC.
+	config["network_interface"] = []interface{}{
+		map[string]interface{}{
+			"network": fakeFabricNetworkID,
+			"ip":      "192.168.128.20",
+		},
+		map[string]interface{}{
+			"network": fakePrivateNetworkID,
+		},
+		map[string]interface{}{
+			"network": fakePublicNetworkID,
+		},
+	}
+	_, err = testFakePlan(r, state, config, meta)
+	if err == nil || !strings.Contains(err.Error(), created[0]["mac"].(string)) {
+		t.Fatalf("expected making the existing fabric NIC primary to be rejected, got %v", err)
+	}
+
+	config["network_interface"].([]interface{})[1].(map[string]interface{})["primary"] = true
+	config["network_interface"].([]interface{})[2].(map[string]interface{})["primary"] = true
+	if _, err := testFakePlan(r, state, config, meta); err == nil {
+		t.Fatal("expected a plan error with two primary interfaces")
+	}
+}
+
+func TestPlanMachineNICs(t *testing.T) {
+	nics := []*compute.NIC{
+		{MAC: "90:b8:d0:00:00:01", IP: "192.168.128.20", Network: fakeFabricNetworkID, Primary: true},
+		{MAC: "90:b8:d0:00:00:02", IP: "192.168.128.21", Network: fakeFabricNetworkID},
+		{MAC: "90:b8:d0:00:00:03", IP: "10.64.0.5", Network: fakePrivateNetworkID},
+	}
+	nicNetworks := map[string]string{}
+	for _, nic := range nics {
+		nicNetworks[nic.MAC] = nic.Network
+	}
+
+	// Of two NICs on the same network, the one with the requested address
+	// is kept even when an interface without an address comes first.
+	plan := planMachineNICs([]machineNetwork{
+		{Network: fakeFabricNetworkID},
+		{Network: fakeFabricNetworkID, IP: "192.168.128.21", Primary: true},
+		{Network: fakePublicNetworkID},
+	}, nics, nicNetworks)
+	if len(plan.add) != 1 || plan.add[0].Network != fakePublicNetworkID {
+		t.Fatalf("expected only a public NIC to be added, got %v", plan.add)
+	}
+	if plan.primary != "90:b8:d0:00:00:02" {
+		t.Fatalf("expected the NIC with IP 192.168.128.21 to be made primary, got %q", plan.primary)
+	}
+	if len(plan.remove) != 1 || plan.remove[0] != "90:b8:d0:00:00:03" {
+		t.Fatalf("expected only the private NIC to be removed, got %v", plan.remove)
+	}
+
+	// A third interface on the fabric network needs a new NIC.
+	plan = planMachineNICs([]machineNetwork{
+		{Network: fakeFabricNetworkID, Primary: true},
+		{Network: fakeFabricNetworkID},
+		{Network: fakeFabricNetworkID},
+		{Network: fakePrivateNetworkID},
+	}, nics, nicNetworks)
+	if len(plan.add) != 1 || plan.add[0].Network != fakeFabricNetworkID || plan.primary != "" || len(plan.remove) != 0 {
+		t.Fatalf("expected only a fabric NIC to be added, got %+v", plan)
+	}
+}
+
+func TestFakeTritonMachine_desiredState(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+	r := resourceMachine()
+
+	config := map[string]interface{}{
+		"name":          "fake-machine",
+		"package":       "g1.nano",
+		"image":         fakeImageBase64LTSID,
+		"desired_state": "stopped",
+	}
+
+	state, err := testFakeApply(r, nil, config, meta)
+	if err != nil {
+		t.Fatalf("error creating machine: %s", err)
+	}
+	if state.Attributes["state"] != machineStateStopped {
+		t.Fatalf("expected machine to be created stopped, got %q", state.Attributes["state"])
+	}
+
+	config["desired_state"] = "running"
+	state, err = testFakeApply(r, state, config, meta)
+	if err != nil {
+		t.Fatalf("error starting machine: %s", err)
+	}
+	if state.Attributes["state"] != machineStateRunning {
+		t.Fatalf("expected machine to be running, got %q", state.Attributes["state"])
+	}
+
+	config["reboot_trigger"] = map[string]interface{}{"serial": "1"}
+	state, err = testFakeApply(r, state, config, meta)
+	if err != nil {
+		t.Fatalf("error rebooting machine: %s", err)
+	}
+	if reboots := f.machines[state.ID].reboots; reboots != 1 {
+		t.Fatalf("expected machine to be rebooted once, got %d", reboots)
+	}
+	if state.Attributes["state"] != machineStateRunning {
+		t.Fatalf("expected the apply to wait for the machine to go down and come back, got %q", state.Attributes["state"])
+	}
+
+	// Stopping the machine out of band must show up as drift.
+	f.machines[state.ID].State = machineStateStopped
+	state, err = testFakeRefresh(r, state, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff, err := testFakePlan(r, state, config, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff.Empty() || diff.Attributes["desired_state"] == nil {
+		t.Fatalf("expected a desired_state diff after the machine was stopped, got %#v", diff)
+	}
+
+	// A stopped machine cannot be rebooted.
+	config["desired_state"] = "stopped"
+	config["reboot_trigger"] = map[string]interface{}{"serial": "2"}
+	if _, err := testFakePlan(r, state, config, meta); err == nil || !strings.Contains(err.Error(), "reboot_trigger cannot reboot") {
+		t.Fatalf("expected a reboot of a stopped machine to be rejected, got %v", err)
+	}
+
+	if _, errs := resourceMachineValidateDesiredState("paused", "desired_state"); len(errs) == 0 {
+		t.Fatal("expected desired_state \"paused\" to be rejected")
+	}
+
+	if err := testFakeDestroy(r, state, meta); err != nil {
+		t.Fatalf("error destroying machine: %s", err)
+	}
+}
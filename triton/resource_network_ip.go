@@ -0,0 +1,159 @@
+package triton
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/TritonDataCenter/triton-go/errors"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceNetworkIP() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceNetworkIPCreate,
+		Read:   resourceNetworkIPRead,
+		Delete: resourceNetworkIPDelete,
+		Importer: &schema.ResourceImporter{
+			State: func(d *schema.ResourceData, meta any) ([]*schema.ResourceData, error) {
+				networkID, ip, err := resourceNetworkIPParseIds(d.Id())
+				if err != nil {
+					return nil, err
+				}
+
+				d.Set("network_id", networkID)
+				d.Set("ip", ip)
+
+				return []*schema.ResourceData{d}, nil
+			},
+		},
+
+		Schema: map[string]*schema.Schema{
+			"network_id": {
+				Description: "The ID of the network the IP address belongs to.",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+
+			"ip": {
+				Description:  "The IP address to reserve.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validateIPv4Address,
+			},
+
+			// Computed parameters
+			"managed": {
+				Description: "Whether the IP address is managed by Triton, such as a gateway or broadcast address.",
+				Type:        schema.TypeBool,
+				Computed:    true,
+			},
+
+			"owner": {
+				Description: "The UUID of the account which owns the IP address.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+
+			"belongs_to_uuid": {
+				Description: "The UUID of the machine or other object the IP address is in use by.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+
+			"belongs_to_type": {
+				Description: "The type of the object the IP address is in use by, e.g. `zone`.",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func resourceNetworkIPCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	n, err := client.Network()
+	if err != nil {
+		return err
+	}
+
+	networkID := d.Get("network_id").(string)
+	ip := d.Get("ip").(string)
+
+	log.Printf("[DEBUG] Reserving IP %s on network %q", ip, networkID)
+	if _, err := updateNetworkIP(context.Background(), n, networkID, ip, true); err != nil {
+		return err
+	}
+
+	d.SetId(fmt.Sprintf("%s.%s", networkID, ip))
+
+	return resourceNetworkIPRead(d, meta)
+}
+
+func resourceNetworkIPRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	n, err := client.Network()
+	if err != nil {
+		return err
+	}
+
+	networkID := d.Get("network_id").(string)
+	ip := d.Get("ip").(string)
+
+	networkIP, err := getNetworkIP(context.Background(), n, networkID, ip)
+	if err != nil {
+		if errors.IsSpecificStatusCode(err, http.StatusNotFound) || errors.IsSpecificStatusCode(err, http.StatusGone) {
+			log.Printf("[DEBUG] Network %q or IP %s not found", networkID, ip)
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	// An address released outside of Terraform has to be reserved again.
+	if !networkIP.Reserved {
+		log.Printf("[DEBUG] IP %s on network %q is no longer reserved", ip, networkID)
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("managed", networkIP.Managed)
+	d.Set("owner", networkIP.OwnerUUID)
+	d.Set("belongs_to_uuid", networkIP.BelongsToUUID)
+	d.Set("belongs_to_type", networkIP.BelongsToType)
+
+	return nil
+}
+
+func resourceNetworkIPDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	n, err := client.Network()
+	if err != nil {
+		return err
+	}
+
+	networkID := d.Get("network_id").(string)
+	ip := d.Get("ip").(string)
+
+	log.Printf("[DEBUG] Releasing IP %s on network %q", ip, networkID)
+	_, err = updateNetworkIP(context.Background(), n, networkID, ip, false)
+	if err != nil && (errors.IsSpecificStatusCode(err, http.StatusNotFound) || errors.IsSpecificStatusCode(err, http.StatusGone)) {
+		return nil
+	}
+
+	return err
+}
+
+func resourceNetworkIPParseIds(id string) (string, string, error) {
+	parts := strings.SplitN(id, ".", 2)
+
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("unexpected format of ID (%s), expected networkId.ip", id)
+	}
+
+	return parts[0], parts[1], nil
+}
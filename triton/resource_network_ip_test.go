@@ -0,0 +1,158 @@
+package triton
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccTritonNetworkIP_basic(t *testing.T) {
+	networkName := testAccConfig(t, "test_network_name")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckTritonNetworkIPDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTritonNetworkIP_basic(networkName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("triton_network_ip.test", "owner"),
+					resource.TestCheckResourceAttr("triton_network_ip.test", "managed", "false"),
+				),
+			},
+			{
+				ResourceName:      "triton_network_ip.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testCheckTritonNetworkIPDestroy(s *terraform.State) error {
+	conn := testAccProvider.Meta().(*Client)
+	n, err := conn.Network()
+	if err != nil {
+		return err
+	}
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "triton_network_ip" {
+			continue
+		}
+
+		networkIP, err := getNetworkIP(context.Background(), n, rs.Primary.Attributes["network_id"], rs.Primary.Attributes["ip"])
+		if err != nil {
+			return err
+		}
+
+		if networkIP.Reserved {
+			return fmt.Errorf("Bad: IP %s is still reserved", networkIP.IP)
+		}
+	}
+
+	return nil
+}
+
+var testAccTritonNetworkIP_basic = func(networkName string) string {
+	return fmt.Sprintf(`
+		data "triton_network" "test" {
+			name = "%s"
+		}
+
+		resource "triton_network_ip" "test" {
+			network_id = data.triton_network.test.id
+			ip = cidrhost(data.triton_network.test.subnet, -10)
+		}
+	`, networkName)
+}
+
+func TestFakeTritonNetworkIP_basic(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+	r := resourceNetworkIP()
+
+	// Reserve the first address machines would otherwise be given.
+	config := map[string]interface{}{
+		"network_id": fakeFabricNetworkID,
+		"ip":         "192.168.128.5",
+	}
+	state, err := testFakeApply(r, nil, config, meta)
+	if err != nil {
+		t.Fatalf("error reserving IP: %s", err)
+	}
+	if state.ID != fakeFabricNetworkID+".192.168.128.5" {
+		t.Fatalf("unexpected network IP ID %q", state.ID)
+	}
+	if state.Attributes["owner"] != fakeAccountID || state.Attributes["belongs_to_uuid"] != "" {
+		t.Fatalf("unexpected attributes of the reserved IP: %v", state.Attributes)
+	}
+
+	machine, err := testFakeApply(resourceMachine(), nil, map[string]interface{}{
+		"package":  "g1.nano",
+		"image":    fakeImageBase64LTSID,
+		"networks": []interface{}{fakeFabricNetworkID},
+	}, meta)
+	if err != nil {
+		t.Fatalf("error creating machine: %s", err)
+	}
+	if machine.Attributes["primaryip"] == "192.168.128.5" {
+		t.Fatal("expected the reserved IP not to be handed out to a machine")
+	}
+
+	ips, err := testFakeRead(dataSourceNetworkIPs(), map[string]interface{}{
+		"network_id": fakeFabricNetworkID,
+	}, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := map[string]map[string]interface{}{}
+	for _, ip := range dataSourceNetworkIPs().Data(ips).Get("ips").([]interface{}) {
+		found[ip.(map[string]interface{})["ip"].(string)] = ip.(map[string]interface{})
+	}
+	if ip := found["192.168.128.5"]; ip == nil || ip["reserved"] != true {
+		t.Fatalf("expected the reserved IP to be listed, got %v", found)
+	}
+	if ip := found[machine.Attributes["primaryip"]]; ip == nil || ip["belongs_to_uuid"] != machine.ID || ip["belongs_to_type"] != "zone" {
+		t.Fatalf("expected the IP of the machine to be listed, got %v", found)
+	}
+	if ip := found["192.168.128.1"]; ip == nil || ip["managed"] != true {
+		t.Fatalf("expected the gateway to be listed as managed, got %v", found)
+	}
+
+	if err := testFakeDestroy(r, state, meta); err != nil {
+		t.Fatalf("error releasing IP: %s", err)
+	}
+	if f.networks[fakeFabricNetworkID].reserved["192.168.128.5"] {
+		t.Fatal("expected the IP to be released")
+	}
+
+	// A released address drops out of state, so that it is reserved again.
+	state, err = testFakeRefresh(r, state, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != nil && state.ID != "" {
+		t.Fatal("expected the released IP to be removed from state")
+	}
+}
+
+func TestResourceNetworkIPParseIds(t *testing.T) {
+	networkID, ip, err := resourceNetworkIPParseIds(fakeFabricNetworkID + ".192.168.128.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if networkID != fakeFabricNetworkID || ip != "192.168.128.5" {
+		t.Fatalf("unexpected IDs %q and %q", networkID, ip)
+	}
+
+	for _, id := range []string{"", fakeFabricNetworkID, ".192.168.128.5", fakeFabricNetworkID + "."} {
+		if _, _, err := resourceNetworkIPParseIds(id); err == nil {
+			t.Errorf("expected %q to be rejected", id)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package triton
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/TritonDataCenter/triton-go/compute"
+	"github.com/TritonDataCenter/triton-go/errors"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func resourceSnapshotRollback() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceSnapshotRollbackCreate,
+		Read:   resourceSnapshotRollbackRead,
+		Delete: resourceSnapshotRollbackDelete,
+
+		Schema: map[string]*schema.Schema{
+			"machine_id": {
+				Description: "The ID of the machine to roll back.",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+
+			"snapshot_name": {
+				Description: "The name of the snapshot to start the machine from.",
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+			},
+
+			"triggers": {
+				Description: "Arbitrary map of values that, when changed, will roll the machine back again.",
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func resourceSnapshotRollbackCreate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	c, err := client.Compute()
+	if err != nil {
+		return err
+	}
+
+	machineID := d.Get("machine_id").(string)
+	snapshotName := d.Get("snapshot_name").(string)
+
+	snapshot, err := c.Snapshots().Get(context.Background(), &compute.GetSnapshotInput{
+		MachineID: machineID,
+		Name:      snapshotName,
+	})
+	if err != nil {
+		return err
+	}
+	if snapshot.State != "created" {
+		return fmt.Errorf("snapshot %q of machine %q is %q, it must be \"created\" to roll back to it", snapshotName, machineID, snapshot.State)
+	}
+
+	inst, err := c.Instances().Get(context.Background(), &compute.GetInstanceInput{
+		ID: machineID,
+	})
+	if err != nil {
+		return err
+	}
+
+	// CloudAPI only starts a machine from a snapshot when it is stopped.
+	if inst.State != machineStateStopped {
+		log.Printf("[INFO] Stopping machine %q before rolling back to snapshot %q", machineID, snapshotName)
+		err := c.Instances().Stop(context.Background(), &compute.StopInstanceInput{
+			InstanceID: machineID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := waitForMachineState(c, machineID, machineStateStopped); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("[INFO] Starting machine %q from snapshot %q", machineID, snapshotName)
+	err = c.Snapshots().StartMachine(context.Background(), &compute.StartMachineFromSnapshotInput{
+		MachineID: machineID,
+		Name:      snapshotName,
+	})
+	if err != nil {
+		return err
+	}
+
+	d.SetId(fmt.Sprintf("%s.%s", machineID, snapshotName))
+
+	if err := waitForMachineState(c, machineID, machineStateRunning); err != nil {
+		return err
+	}
+
+	return resourceSnapshotRollbackRead(d, meta)
+}
+
+func resourceSnapshotRollbackRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	c, err := client.Compute()
+	if err != nil {
+		return err
+	}
+
+	// A rollback is a one-off action; it only disappears from state once the
+	// snapshot it refers to (or the machine itself) is gone.
+	_, err = c.Snapshots().Get(context.Background(), &compute.GetSnapshotInput{
+		MachineID: d.Get("machine_id").(string),
+		Name:      d.Get("snapshot_name").(string),
+	})
+	if err != nil {
+		if errors.IsSpecificStatusCode(err, http.StatusNotFound) || errors.IsSpecificStatusCode(err, http.StatusGone) {
+			log.Printf("[DEBUG] Snapshot %q of machine %q no longer exists", d.Get("snapshot_name"), d.Get("machine_id"))
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	return nil
+}
+
+func resourceSnapshotRollbackDelete(d *schema.ResourceData, meta interface{}) error {
+	// Nothing to undo: destroying a rollback leaves the machine as it is.
+	d.SetId("")
+
+	return nil
+}
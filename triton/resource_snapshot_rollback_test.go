@@ -0,0 +1,138 @@
+package triton
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/TritonDataCenter/triton-go/compute"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccTritonSnapshotRollback_basic(t *testing.T) {
+	snapshotName := fmt.Sprintf("acctest-snap-%d", acctest.RandInt())
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckTritonSnapshotDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTritonSnapshotRollbackConfig(t, snapshotName, "1"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckTritonSnapshotExists("triton_snapshot.test"),
+					testCheckTritonSnapshotRollbackRunning("triton_snapshot_rollback.test"),
+				),
+			},
+			{
+				Config: testAccTritonSnapshotRollbackConfig(t, snapshotName, "2"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckTritonSnapshotRollbackRunning("triton_snapshot_rollback.test"),
+					resource.TestCheckResourceAttr("triton_snapshot_rollback.test", "triggers.attempt", "2"),
+				),
+			},
+		},
+	})
+}
+
+func testCheckTritonSnapshotRollbackRunning(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+		conn := testAccProvider.Meta().(*Client)
+		c, err := conn.Compute()
+		if err != nil {
+			return err
+		}
+
+		inst, err := c.Instances().Get(context.Background(), &compute.GetInstanceInput{
+			ID: rs.Primary.Attributes["machine_id"],
+		})
+		if err != nil {
+			return fmt.Errorf("Bad: Check Snapshot Rollback: %s", err)
+		}
+
+		if inst.State != machineStateRunning {
+			return fmt.Errorf("Bad: Machine %q is %q after rollback", inst.ID, inst.State)
+		}
+
+		return nil
+	}
+}
+
+func testAccTritonSnapshotRollbackConfig(t *testing.T, snapshotName string, attempt string) string {
+	return testAccTritonSnapshotConfig(t, snapshotName) + fmt.Sprintf(`
+		resource "triton_snapshot_rollback" "test" {
+		  machine_id = "${triton_machine.test.id}"
+		  snapshot_name = "${triton_snapshot.test.name}"
+
+		  triggers = {
+		    attempt = "%s"
+		  }
+		}
+	`, attempt)
+}
+
+func TestFakeTritonSnapshotRollback_basic(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+
+	machine, err := testFakeApply(resourceMachine(), nil, map[string]interface{}{
+		"package": "g1.nano",
+		"image":   fakeImageBase64LTSID,
+	}, meta)
+	if err != nil {
+		t.Fatalf("error creating machine: %s", err)
+	}
+
+	snapshot, err := testFakeApply(resourceSnapshot(), nil, map[string]interface{}{
+		"name":       "before-upgrade",
+		"machine_id": machine.ID,
+	}, meta)
+	if err != nil {
+		t.Fatalf("error creating snapshot: %s", err)
+	}
+
+	r := resourceSnapshotRollback()
+	config := map[string]interface{}{
+		"machine_id":    machine.ID,
+		"snapshot_name": snapshot.ID,
+	}
+
+	state, err := testFakeApply(r, nil, config, meta)
+	if err != nil {
+		t.Fatalf("error rolling back machine: %s", err)
+	}
+	if state.ID != fmt.Sprintf("%s.%s", machine.ID, snapshot.ID) {
+		t.Fatalf("unexpected rollback ID %q", state.ID)
+	}
+
+	m := f.machines[machine.ID]
+	if m.State != machineStateRunning || len(m.rollbacks) != 1 || m.rollbacks[0] != "before-upgrade" {
+		t.Fatalf("expected machine to be started from the snapshot, got state %q and rollbacks %v", m.State, m.rollbacks)
+	}
+
+	config["triggers"] = map[string]interface{}{"attempt": "2"}
+	diff, err := testFakePlan(r, state, config, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.RequiresNew() {
+		t.Fatal("expected a change of triggers to require a new rollback")
+	}
+
+	if err := testFakeDestroy(resourceSnapshot(), snapshot, meta); err != nil {
+		t.Fatalf("error destroying snapshot: %s", err)
+	}
+	state, err = testFakeRefresh(r, state, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != nil && state.ID != "" {
+		t.Fatal("expected rollback to be removed from state with its snapshot")
+	}
+}
@@ -2,10 +2,18 @@ package triton
 
 import (
 	"context"
-	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
 	"strconv"
+	"strings"
 
+	"github.com/TritonDataCenter/triton-go/compute"
+	"github.com/TritonDataCenter/triton-go/errors"
 	"github.com/TritonDataCenter/triton-go/network"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
@@ -21,6 +29,8 @@ func resourceVLAN() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
+		CustomizeDiff: resourceVLANCustomizeDiff,
+
 		Schema: map[string]*schema.Schema{
 			"vlan_id": {
 				Description: "Number between 0-4095 indicating VLAN ID",
@@ -30,7 +40,7 @@ func resourceVLAN() *schema.Resource {
 				ValidateFunc: func(val interface{}, field string) (warn []string, err []error) {
 					value := val.(int)
 					if value < 0 || value > 4095 {
-						err = append(err, errors.New("vlan_id must be between 0 and 4095"))
+						err = append(err, fmt.Errorf("vlan_id must be between 0 and 4095"))
 					}
 					return
 				},
@@ -45,6 +55,70 @@ func resourceVLAN() *schema.Resource {
 				Optional:    true,
 				Type:        schema.TypeString,
 			},
+			"network": {
+				Description: "Fabric networks on the VLAN, told apart by their subnets",
+				Optional:    true,
+				Type:        schema.TypeList,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Description: "ID of the network",
+							Computed:    true,
+							Type:        schema.TypeString,
+						},
+						"name": {
+							Description: "Network name",
+							Required:    true,
+							Type:        schema.TypeString,
+						},
+						"description": {
+							Description: "Description of network",
+							Optional:    true,
+							Type:        schema.TypeString,
+						},
+						"subnet": {
+							Description: "CIDR formatted string describing network address space. Changing this replaces the network",
+							Required:    true,
+							Type:        schema.TypeString,
+						},
+						"provision_start_ip": {
+							Description: "First IP on the network that can be assigned",
+							Required:    true,
+							Type:        schema.TypeString,
+						},
+						"provision_end_ip": {
+							Description: "Last assignable IP on the network",
+							Required:    true,
+							Type:        schema.TypeString,
+						},
+						"gateway": {
+							Description: "Gateway IP. Changing this replaces the network",
+							Optional:    true,
+							Type:        schema.TypeString,
+						},
+						"resolvers": {
+							Description: "List of IP addresses for DNS resolvers",
+							Optional:    true,
+							Computed:    true,
+							Type:        schema.TypeList,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+						"routes": {
+							Description: "Map of CIDR block to Gateway IP address",
+							Optional:    true,
+							Computed:    true,
+							Type:        schema.TypeMap,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+						"internet_nat": {
+							Description: "Whether or not a NAT zone is provisioned at the Gateway IP address. Changing this replaces the network",
+							Optional:    true,
+							Default:     true,
+							Type:        schema.TypeBool,
+						},
+					},
+				},
+			},
 		},
 	}
 }
@@ -66,6 +140,11 @@ func resourceVLANCreate(d *schema.ResourceData, meta interface{}) error {
 	}
 
 	d.SetId(strconv.Itoa(vlan.ID))
+
+	if err := resourceVLANUpdateNetworks(d, meta); err != nil {
+		return err
+	}
+
 	return resourceVLANRead(d, meta)
 }
 
@@ -109,7 +188,30 @@ func resourceVLANRead(d *schema.ResourceData, meta interface{}) error {
 	d.Set("name", vlan.Name)
 	d.Set("description", vlan.Description)
 
-	return nil
+	// Only the networks created through the `network` blocks are
+	// read back, so that networks managed by triton_fabric are left alone.
+	networks := []interface{}{}
+	for _, v := range d.Get("network").([]interface{}) {
+		m, _ := v.(map[string]interface{})
+		if m == nil || m["id"].(string) == "" {
+			continue
+		}
+
+		fabric, err := n.Fabrics().Get(context.Background(), &network.GetFabricInput{
+			FabricVLANID: vlan.ID,
+			NetworkID:    m["id"].(string),
+		})
+		if err != nil {
+			if errors.IsSpecificStatusCode(err, http.StatusNotFound) {
+				log.Printf("[DEBUG] triton_vlan: network %q of VLAN %d no longer exists", m["id"].(string), vlan.ID)
+				continue
+			}
+			return err
+		}
+		networks = append(networks, flattenVLANNetwork(fabric))
+	}
+
+	return d.Set("network", networks)
 }
 
 func resourceVLANUpdate(d *schema.ResourceData, meta interface{}) error {
@@ -119,16 +221,25 @@ func resourceVLANUpdate(d *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 
-	vlan, err := n.Fabrics().UpdateVLAN(context.Background(), &network.UpdateVLANInput{
-		ID:          d.Get("vlan_id").(int),
-		Name:        d.Get("name").(string),
-		Description: d.Get("description").(string),
-	})
-	if err != nil {
-		return err
+	if d.HasChanges("name", "description") {
+		vlan, err := n.Fabrics().UpdateVLAN(context.Background(), &network.UpdateVLANInput{
+			ID:          d.Get("vlan_id").(int),
+			Name:        d.Get("name").(string),
+			Description: d.Get("description").(string),
+		})
+		if err != nil {
+			return err
+		}
+
+		d.SetId(strconv.Itoa(vlan.ID))
+	}
+
+	if d.HasChange("network") {
+		if err := resourceVLANUpdateNetworks(d, meta); err != nil {
+			return err
+		}
 	}
 
-	d.SetId(strconv.Itoa(vlan.ID))
 	return resourceVLANRead(d, meta)
 }
 
@@ -144,6 +255,17 @@ func resourceVLANDelete(d *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 
+	// The networks have to go before the VLAN can.
+	for _, v := range d.Get("network").([]interface{}) {
+		m, _ := v.(map[string]interface{})
+		if m == nil || m["id"].(string) == "" {
+			continue
+		}
+		if err := deleteVLANNetwork(d, meta, id, m["id"].(string), m["name"].(string)); err != nil {
+			return err
+		}
+	}
+
 	return n.Fabrics().DeleteVLAN(context.Background(), &network.DeleteVLANInput{
 		ID: id,
 	})
@@ -157,3 +279,358 @@ func resourceVLANIDInt(id string) (int, error) {
 
 	return int(result), nil
 }
+
+// resourceVLANCustomizeDiff checks the addressing of the `network` blocks at
+// plan time, the same way triton_fabric does for a single network, and that
+// their subnets neither repeat nor overlap.
+func resourceVLANCustomizeDiff(_ context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("network") {
+		return nil
+	}
+
+	var subnets []*net.IPNet
+	var names []string
+	for i, v := range d.Get("network").([]interface{}) {
+		m, _ := v.(map[string]interface{})
+		if m == nil {
+			continue
+		}
+		for _, key := range []string{"name", "subnet", "provision_start_ip", "provision_end_ip", "gateway", "routes"} {
+			if !d.NewValueKnown(fmt.Sprintf("network.%d.%s", i, key)) {
+				return nil
+			}
+		}
+
+		subnet, err := validateFabricAddressing(
+			m["subnet"].(string),
+			m["provision_start_ip"].(string),
+			m["provision_end_ip"].(string),
+			m["gateway"].(string),
+			m["routes"].(map[string]interface{}),
+		)
+		if err != nil {
+			return fmt.Errorf("network %q: %s", m["name"].(string), err)
+		}
+
+		for j, other := range subnets {
+			if other.Contains(subnet.IP) || subnet.Contains(other.IP) {
+				return fmt.Errorf("network %q: subnet %s overlaps subnet %s of network %q", m["name"].(string), subnet, other, names[j])
+			}
+		}
+		for _, name := range names {
+			if name == m["name"].(string) {
+				return fmt.Errorf("network %q is declared more than once", name)
+			}
+		}
+		subnets = append(subnets, subnet)
+		names = append(names, m["name"].(string))
+	}
+
+	return nil
+}
+
+// resourceVLANUpdateNetworks brings the networks of the VLAN in line with the
+// `network` blocks. Networks are told apart by their subnets: networks which
+// are no longer wanted, or whose subnet, gateway or NAT zone changed, are
+// deleted first so that their subnets are free again, the others are updated
+// in place, and new networks are created last. A wanted subnet which is
+// already taken by a network the resource does not manage is an error, as
+// the network would otherwise be deleted along with the VLAN.
+func resourceVLANUpdateNetworks(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	n, err := client.Network()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	vlanID := d.Get("vlan_id").(int)
+
+	o, _ := d.GetChange("network")
+	current := map[string]map[string]interface{}{}
+	managed := map[string]bool{}
+	var tracked []interface{}
+	for _, v := range o.([]interface{}) {
+		if m, _ := v.(map[string]interface{}); m != nil && m["id"].(string) != "" {
+			current[canonicalSubnet(m["subnet"].(string))] = m
+			managed[m["id"].(string)] = true
+			tracked = append(tracked, m)
+		}
+	}
+
+	// On failure, the networks which are known to be managed, including
+	// those created before the failure, are kept in state, so that the next
+	// apply does not take them for networks managed elsewhere.
+	fail := func(err error) error {
+		if tracked == nil {
+			tracked = []interface{}{}
+		}
+		d.Set("network", tracked)
+		return err
+	}
+
+	desired := d.Get("network").([]interface{})
+	if len(desired) == 0 && len(tracked) == 0 {
+		return nil
+	}
+	config := d.GetRawConfig().GetAttr("network")
+	keep := map[string]bool{}
+	for _, v := range desired {
+		want, _ := v.(map[string]interface{})
+		if want == nil {
+			continue
+		}
+		if have, ok := current[canonicalSubnet(want["subnet"].(string))]; ok &&
+			have["gateway"].(string) == want["gateway"].(string) &&
+			have["internet_nat"].(bool) == want["internet_nat"].(bool) {
+			keep[have["id"].(string)] = true
+		}
+	}
+
+	for _, m := range append([]interface{}(nil), tracked...) {
+		m := m.(map[string]interface{})
+		if keep[m["id"].(string)] {
+			continue
+		}
+		if err := deleteVLANNetwork(d, meta, vlanID, m["id"].(string), m["name"].(string)); err != nil {
+			return fail(err)
+		}
+		for i, v := range tracked {
+			if v.(map[string]interface{})["id"] == m["id"] {
+				tracked = append(tracked[:i], tracked[i+1:]...)
+				break
+			}
+		}
+	}
+
+	fabrics, err := n.Fabrics().List(ctx, &network.ListFabricsInput{
+		FabricVLANID: vlanID,
+	})
+	if err != nil {
+		return fail(err)
+	}
+	existing := map[string]*network.Network{}
+	for _, fabric := range fabrics {
+		existing[canonicalSubnet(fabric.Subnet)] = fabric
+	}
+
+	for i, v := range desired {
+		want, _ := v.(map[string]interface{})
+		if want == nil {
+			continue
+		}
+
+		var element cty.Value
+		if !config.IsNull() && config.IsKnown() && config.LengthInt() > i {
+			element = config.Index(cty.NumberIntVal(int64(i)))
+		}
+		changes := expandVLANNetwork(want, element)
+
+		fabric, ok := existing[canonicalSubnet(want["subnet"].(string))]
+		if !ok {
+			input := &network.CreateFabricInput{
+				FabricVLANID:     vlanID,
+				Name:             want["name"].(string),
+				Description:      want["description"].(string),
+				Subnet:           want["subnet"].(string),
+				ProvisionStartIP: want["provision_start_ip"].(string),
+				ProvisionEndIP:   want["provision_end_ip"].(string),
+				Gateway:          want["gateway"].(string),
+				InternetNAT:      want["internet_nat"].(bool),
+			}
+			if resolvers, ok := changes["resolvers"].([]string); ok {
+				input.Resolvers = resolvers
+			}
+			if routes, ok := changes["routes"].(map[string]string); ok {
+				input.Routes = routes
+			}
+
+			log.Printf("[DEBUG] triton_vlan: creating network %q on VLAN %d", input.Name, vlanID)
+			created, err := n.Fabrics().Create(ctx, input)
+			if err != nil {
+				return fail(err)
+			}
+			tracked = append(tracked, flattenVLANNetwork(created))
+			continue
+		}
+
+		if !managed[fabric.Id] {
+			return fail(fmt.Errorf("network %q: subnet %s is already taken by network %q (%s) on VLAN %d, which this resource does not manage; "+
+				"delete that network, or manage it with a triton_fabric resource instead, e.g. `terraform import triton_fabric.<name> %d/%s`",
+				want["name"].(string), fabric.Subnet, fabric.Name, fabric.Id, vlanID, vlanID, fabric.Name))
+		}
+		if fabric.Gateway != want["gateway"].(string) || fabric.InternetNAT != want["internet_nat"].(bool) {
+			return fail(fmt.Errorf("network %q with subnet %s is already on VLAN %d with a different gateway or NAT zone", want["name"].(string), fabric.Subnet, vlanID))
+		}
+
+		// Only send what differs from the network as it is.
+		have := flattenVLANNetwork(fabric)
+		for key, value := range changes {
+			if fmt.Sprint(have[key]) == fmt.Sprint(value) {
+				delete(changes, key)
+			}
+		}
+		if len(changes) > 0 {
+			log.Printf("[DEBUG] triton_vlan: updating network %q on VLAN %d", fabric.Id, vlanID)
+			if _, err := updateFabric(ctx, n, vlanID, fabric.Id, changes); err != nil {
+				return fail(err)
+			}
+		}
+	}
+
+	// Track the networks in configuration order, which Read keeps.
+	fabrics, err = n.Fabrics().List(ctx, &network.ListFabricsInput{
+		FabricVLANID: vlanID,
+	})
+	if err != nil {
+		return fail(err)
+	}
+	existing = map[string]*network.Network{}
+	for _, fabric := range fabrics {
+		existing[canonicalSubnet(fabric.Subnet)] = fabric
+	}
+	networks := []interface{}{}
+	for _, v := range desired {
+		if want, _ := v.(map[string]interface{}); want != nil {
+			if fabric, ok := existing[canonicalSubnet(want["subnet"].(string))]; ok {
+				networks = append(networks, flattenVLANNetwork(fabric))
+			}
+		}
+	}
+
+	return d.Set("network", networks)
+}
+
+// expandVLANNetwork returns the fields of a `network` block which can be
+// changed in place. Resolvers and routes are only included when they are
+// configured, as CloudAPI otherwise fills them in.
+func expandVLANNetwork(m map[string]interface{}, config cty.Value) map[string]interface{} {
+	changes := map[string]interface{}{}
+	for _, key := range []string{"name", "description", "provision_start_ip", "provision_end_ip"} {
+		changes[key] = m[key].(string)
+	}
+
+	configured := func(key string) bool {
+		if config == cty.NilVal || config.IsNull() || !config.IsKnown() {
+			return true
+		}
+		v := config.GetAttr(key)
+		return !v.IsNull()
+	}
+
+	if configured("resolvers") {
+		resolvers := []string{}
+		for _, resolver := range m["resolvers"].([]interface{}) {
+			resolvers = append(resolvers, resolver.(string))
+		}
+		changes["resolvers"] = resolvers
+	}
+	if configured("routes") {
+		routes := map[string]string{}
+		for cidr, ip := range m["routes"].(map[string]interface{}) {
+			routes[cidr] = ip.(string)
+		}
+		changes["routes"] = routes
+	}
+
+	return changes
+}
+
+func flattenVLANNetwork(fabric *network.Network) map[string]interface{} {
+	resolvers := []interface{}{}
+	for _, resolver := range fabric.Resolvers {
+		resolvers = append(resolvers, resolver)
+	}
+	routes := map[string]interface{}{}
+	for cidr, ip := range fabric.Routes {
+		routes[cidr] = ip
+	}
+
+	return map[string]interface{}{
+		"id":                 fabric.Id,
+		"name":               fabric.Name,
+		"description":        fabric.Description,
+		"subnet":             fabric.Subnet,
+		"provision_start_ip": fabric.ProvisioningStartIP,
+		"provision_end_ip":   fabric.ProvisioningEndIP,
+		"gateway":            fabric.Gateway,
+		"resolvers":          resolvers,
+		"routes":             routes,
+		"internet_nat":       fabric.InternetNAT,
+	}
+}
+
+// canonicalSubnet returns a subnet in the form net.IPNet prints it, so that
+// subnets can be compared however they were written.
+func canonicalSubnet(subnet string) string {
+	if _, ipNet, err := net.ParseCIDR(subnet); err == nil {
+		return ipNet.String()
+	}
+	return subnet
+}
+
+// deleteVLANNetwork deletes a network of the VLAN. Machines release their
+// NICs asynchronously once they are deleted, so a network in use is retried
+// until the delete timeout, after which the machines still attached to it
+// are reported.
+func deleteVLANNetwork(d *schema.ResourceData, meta interface{}, vlanID int, networkID, name string) error {
+	client := meta.(*Client)
+	n, err := client.Network()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	log.Printf("[DEBUG] triton_vlan: deleting network %q on VLAN %d", networkID, vlanID)
+	err = retry.RetryContext(ctx, d.Timeout(schema.TimeoutDelete), func() *retry.RetryError {
+		err := n.Fabrics().Delete(ctx, &network.DeleteFabricInput{
+			FabricVLANID: vlanID,
+			NetworkID:    networkID,
+		})
+		switch {
+		case err == nil, errors.IsSpecificStatusCode(err, http.StatusNotFound):
+			return nil
+		case errors.IsInvalidArgument(err), errors.IsInUseError(err):
+			return retry.RetryableError(err)
+		default:
+			return retry.NonRetryableError(err)
+		}
+	})
+	if err == nil {
+		return nil
+	}
+	if !errors.IsInvalidArgument(err) && !errors.IsInUseError(err) {
+		return err
+	}
+
+	machines, listErr := listNetworkMachines(ctx, client, networkID)
+	if listErr != nil || len(machines) == 0 {
+		return err
+	}
+	return fmt.Errorf("network %q (%s) on VLAN %d cannot be deleted while machines are attached to it: %s",
+		name, networkID, vlanID, strings.Join(machines, ", "))
+}
+
+// listNetworkMachines returns the names and IDs of the machines which have a
+// NIC on the given network.
+func listNetworkMachines(ctx context.Context, client *Client, networkID string) ([]string, error) {
+	c, err := client.Compute()
+	if err != nil {
+		return nil, err
+	}
+
+	instances, err := c.Instances().List(ctx, &compute.ListInstancesInput{})
+	if err != nil {
+		return nil, err
+	}
+
+	var machines []string
+	for _, instance := range instances {
+		for _, id := range instance.Networks {
+			if id == networkID {
+				machines = append(machines, fmt.Sprintf("%s (%s)", instance.Name, instance.ID))
+				break
+			}
+		}
+	}
+	return machines, nil
+}
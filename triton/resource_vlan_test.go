@@ -3,7 +3,9 @@ package triton
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/TritonDataCenter/triton-go/errors"
@@ -153,3 +155,266 @@ var testAccTritonVLAN_update = func(vlanID int) string {
 	  description = "test vlan 2"
 	}`, vlanID)
 }
+
+func TestFakeTritonVLAN_networks(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+	r := resourceVLAN()
+
+	web := map[string]interface{}{
+		"name":               "web",
+		"subnet":             "10.0.0.0/24",
+		"gateway":            "10.0.0.1",
+		"provision_start_ip": "10.0.0.5",
+		"provision_end_ip":   "10.0.0.250",
+		"resolvers":          []interface{}{"8.8.8.8"},
+	}
+	db := map[string]interface{}{
+		"name":               "db",
+		"subnet":             "10.0.1.0/24",
+		"provision_start_ip": "10.0.1.5",
+		"provision_end_ip":   "10.0.1.250",
+		"internet_nat":       false,
+	}
+	config := map[string]interface{}{
+		"vlan_id":  100,
+		"name":     "fake-vlan",
+		"network":  []interface{}{web, db},
+		"timeouts": map[string]interface{}{"delete": "1s"},
+	}
+
+	state, err := testFakeApply(r, nil, config, meta)
+	if err != nil {
+		t.Fatalf("error creating VLAN: %s", err)
+	}
+	webID, dbID := state.Attributes["network.0.id"], state.Attributes["network.1.id"]
+	if n := f.networks[webID]; n == nil || n.vlanID != 100 || n.Name != "web" || n.Gateway != "10.0.0.1" {
+		t.Fatalf("unexpected web network: %#v", n)
+	}
+	if n := f.networks[dbID]; n == nil || n.Name != "db" || n.InternetNAT {
+		t.Fatalf("unexpected db network: %#v", n)
+	}
+
+	diff, err := testFakePlan(r, state, config, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected an empty plan after create, got %#v", diff)
+	}
+
+	// Rename web in place, move the gateway of db, which replaces it, and
+	// add a third network.
+	web["name"] = "frontend"
+	web["provision_end_ip"] = "10.0.0.200"
+	db["gateway"] = "10.0.1.1"
+	db["provision_start_ip"] = "10.0.1.10"
+	cache := map[string]interface{}{
+		"name":               "cache",
+		"subnet":             "10.0.2.0/24",
+		"provision_start_ip": "10.0.2.5",
+		"provision_end_ip":   "10.0.2.250",
+	}
+	config["network"] = []interface{}{web, db, cache}
+
+	diff, err = testFakePlan(r, state, config, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff.RequiresNew() {
+		t.Fatalf("expected the VLAN to be updated in place, got %#v", diff)
+	}
+	state, err = testFakeApply(r, state, config, meta)
+	if err != nil {
+		t.Fatalf("error updating VLAN: %s", err)
+	}
+	if state.Attributes["network.0.id"] != webID || f.networks[webID].Name != "frontend" || f.networks[webID].ProvisioningEndIP != "10.0.0.200" {
+		t.Fatalf("expected web to be renamed in place, got %v", state.Attributes)
+	}
+	if newDB := state.Attributes["network.1.id"]; newDB == dbID || f.networks[dbID] != nil || f.networks[newDB].Gateway != "10.0.1.1" {
+		t.Fatalf("expected db to be replaced, got %v", state.Attributes)
+	}
+	if state.Attributes["network.2.name"] != "cache" || state.Attributes["network.#"] != "3" {
+		t.Fatalf("expected cache to be created, got %v", state.Attributes)
+	}
+
+	diff, err = testFakePlan(r, state, config, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected an empty plan after update, got %#v", diff)
+	}
+
+	// A machine on a network blocks its deletion, and is reported.
+	machine, err := testFakeApply(resourceMachine(), nil, map[string]interface{}{
+		"name":     "attached",
+		"package":  "g1.nano",
+		"image":    fakeImageBase64LTSID,
+		"networks": []interface{}{webID},
+	}, meta)
+	if err != nil {
+		t.Fatalf("error creating machine: %s", err)
+	}
+	err = testFakeDestroy(r, state, meta)
+	if err == nil || !strings.Contains(err.Error(), "attached ("+machine.ID+")") {
+		t.Fatalf("expected the attached machine to be reported, got %v", err)
+	}
+
+	if err := testFakeDestroy(resourceMachine(), machine, meta); err != nil {
+		t.Fatalf("error destroying machine: %s", err)
+	}
+	if err := testFakeDestroy(r, state, meta); err != nil {
+		t.Fatalf("error destroying VLAN: %s", err)
+	}
+	if len(f.vlans) != 1 || f.vlans[100] != nil {
+		t.Fatalf("expected the VLAN to be deleted, got %#v", f.vlans)
+	}
+}
+
+func TestFakeTritonVLAN_networkValidation(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+	r := resourceVLAN()
+
+	network := func(name, subnet, start, end string) map[string]interface{} {
+		return map[string]interface{}{
+			"name":               name,
+			"subnet":             subnet,
+			"provision_start_ip": start,
+			"provision_end_ip":   end,
+		}
+	}
+	for name, networks := range map[string][]interface{}{
+		"range":     {network("web", "10.0.0.0/24", "10.0.1.5", "10.0.0.250")},
+		"overlap":   {network("web", "10.0.0.0/16", "10.0.0.5", "10.0.0.250"), network("db", "10.0.1.0/24", "10.0.1.5", "10.0.1.250")},
+		"duplicate": {network("web", "10.0.0.0/24", "10.0.0.5", "10.0.0.250"), network("web", "10.0.1.0/24", "10.0.1.5", "10.0.1.250")},
+	} {
+		config := map[string]interface{}{
+			"vlan_id": 100,
+			"name":    "fake-vlan",
+			"network": networks,
+		}
+		if _, err := testFakePlan(r, nil, config, meta); err == nil {
+			t.Errorf("expected the %s networks to be rejected at plan time", name)
+		}
+	}
+}
+
+func TestFakeTritonVLAN_unmanagedNetworks(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+	r := resourceVLAN()
+
+	config := map[string]interface{}{
+		"vlan_id": 100,
+		"name":    "fake-vlan",
+	}
+	if _, err := testFakeApply(r, nil, config, meta); err != nil {
+		t.Fatalf("error creating VLAN: %s", err)
+	}
+	fabric, err := testFakeApply(resourceFabric(), nil, map[string]interface{}{
+		"name":               "web",
+		"vlan_id":            100,
+		"subnet":             "10.0.0.0/24",
+		"gateway":            "10.0.0.1",
+		"provision_start_ip": "10.0.0.5",
+		"provision_end_ip":   "10.0.0.250",
+	}, meta)
+	if err != nil {
+		t.Fatalf("error creating fabric: %s", err)
+	}
+
+	imported, err := r.Importer.State(r.Data(&terraform.InstanceState{ID: "100"}), meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, err := testFakeRefresh(r, imported[0].State(), meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Attributes["network.#"] != "0" {
+		t.Fatalf("expected networks not declared on the VLAN to be left alone, got %v", state.Attributes)
+	}
+
+	// Declaring a network managed elsewhere would make the VLAN delete it.
+	config["network"] = []interface{}{
+		map[string]interface{}{
+			"name":               "web",
+			"subnet":             "10.0.0.0/24",
+			"gateway":            "10.0.0.1",
+			"provision_start_ip": "10.0.0.5",
+			"provision_end_ip":   "10.0.0.250",
+		},
+	}
+	state, err = testFakeApply(r, state, config, meta)
+	if err == nil || !strings.Contains(err.Error(), "terraform import triton_fabric.<name> 100/web") {
+		t.Fatalf("expected the unmanaged network to be refused, got %v", err)
+	}
+	if state.Attributes["network.#"] != "0" {
+		t.Fatalf("expected the unmanaged network to stay out of state, got %v", state.Attributes)
+	}
+	if err := testFakeDestroy(r, state, meta); err == nil {
+		t.Fatal("expected the VLAN to stay while the unmanaged network is on it")
+	}
+	if _, found := f.networks[fabric.ID]; !found {
+		t.Fatal("expected the unmanaged network to be left alone")
+	}
+}
+
+func TestFakeTritonVLAN_partialCreate(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+	r := resourceVLAN()
+
+	// The second network fails to be created.
+	calls := 0
+	routes := f.routes
+	f.routes = append([]fakeRoute{{
+		method:  http.MethodPost,
+		pattern: []string{"fabrics", "default", "vlans", "*", "networks"},
+		handler: func(w http.ResponseWriter, r *http.Request, args []string) {
+			if calls++; calls == 2 {
+				fakeError(w, http.StatusServiceUnavailable, "ServiceUnavailable", "try again")
+				return
+			}
+			f.createFabric(w, r, args)
+		},
+	}}, routes...)
+
+	config := map[string]interface{}{
+		"vlan_id": 100,
+		"name":    "fake-vlan",
+		"network": []interface{}{
+			map[string]interface{}{
+				"name":               "web",
+				"subnet":             "10.0.0.0/24",
+				"provision_start_ip": "10.0.0.5",
+				"provision_end_ip":   "10.0.0.250",
+			},
+			map[string]interface{}{
+				"name":               "db",
+				"subnet":             "10.0.1.0/24",
+				"provision_start_ip": "10.0.1.5",
+				"provision_end_ip":   "10.0.1.250",
+			},
+		},
+	}
+	state, err := testFakeApply(r, nil, config, meta)
+	if err == nil {
+		t.Fatal("expected creating the second network to fail")
+	}
+	if state.ID != "100" || state.Attributes["network.#"] != "1" || state.Attributes["network.0.name"] != "web" {
+		t.Fatalf("expected the network created before the failure to be kept in state, got %v", state.Attributes)
+	}
+
+	// The next apply picks up from there.
+	state, err = testFakeApply(r, state, config, meta)
+	if err != nil {
+		t.Fatalf("error retrying the VLAN: %s", err)
+	}
+	onVLAN := f.sortedNetworks(func(n *fakeNetwork) bool { return n.vlanID == 100 })
+	if state.Attributes["network.#"] != "2" || len(onVLAN) != 2 {
+		t.Fatalf("expected both networks after retrying, got %v", state.Attributes)
+	}
+}
@@ -6,11 +6,14 @@ import (
 	"log"
 	"net/http"
 	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/TritonDataCenter/triton-go/compute"
 	"github.com/TritonDataCenter/triton-go/errors"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 const (
@@ -19,6 +22,8 @@ const (
 	volumeStateDeleting = "deleting"
 	volumeStateFailed   = "failed"
 	volumeStateReady    = "ready"
+
+	volumeSizeRoundingUp = "up"
 )
 
 func resourceVolume() *schema.Resource {
@@ -29,6 +34,8 @@ func resourceVolume() *schema.Resource {
 		Update:   resourceVolumeUpdate,
 		Delete:   resourceVolumeDelete,
 		Timeouts: slowResourceTimeout,
+
+		CustomizeDiff: resourceVolumeCustomizeDiff,
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
@@ -46,20 +53,29 @@ func resourceVolume() *schema.Resource {
 				Type:        schema.TypeList,
 				Optional:    true,
 				Computed:    true,
+				ForceNew:    true,
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
 			},
 			"size": {
-				Description: "The size of the volume (Mb)",
+				Description: "The size of the volume (Mb). Changing this forces a new volume",
 				Type:        schema.TypeInt,
 				Optional:    true,
 				Computed:    true,
+				ForceNew:    true,
+			},
+			"size_rounding": {
+				Description:  "Set to `up` to round `size` up to the next size CloudAPI supports",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{volumeSizeRoundingUp}, false),
 			},
 			"tags": {
-				Description: "Volume tags",
+				Description: "Volume tags. Changing this forces a new volume",
 				Type:        schema.TypeMap,
 				Optional:    true,
+				ForceNew:    true,
 			},
 			"type": {
 				Description: "Type of volume",
@@ -209,53 +225,113 @@ func resourceVolumeRead(d *schema.ResourceData, meta interface{}) error {
 	return tritonVolumeToTerraformVolume(d, volume)
 }
 
-func resourceVolumeUpdate(d *schema.ResourceData, meta interface{}) error {
+// resourceVolumeCustomizeDiff rejects a configured size CloudAPI does not
+// support for the volume type, or rounds it up to the next supported size
+// when `size_rounding` is "up". A size which the volume already has is taken
+// as it is, and so is any size when the sizes cannot be listed, except that
+// a volume which is larger than the size it is rounded up from is kept.
+func resourceVolumeCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	config := d.GetRawConfig()
+	if config.IsNull() || !config.GetAttr("size").IsKnown() || config.GetAttr("size").IsNull() || !d.NewValueKnown("type") {
+		return nil
+	}
+
+	want := d.Get("size").(int)
+	if o, _ := d.GetChange("size"); d.Id() != "" && o.(int) == want {
+		return nil
+	}
+
 	client := meta.(*Client)
 	c, err := client.Compute()
 	if err != nil {
 		return err
 	}
 
-	d.Partial(true)
-
-	if d.HasChange("name") && !d.IsNewResource() {
-		oldNameInterface, newNameInterface := d.GetChange("name")
-		oldName := oldNameInterface.(string)
-		newName := newNameInterface.(string)
-
-		err := c.Volumes().Update(context.Background(), &compute.UpdateVolumeInput{
-			ID:   d.Id(),
-			Name: newName,
-		})
-		if err != nil {
-			return err
+	volumeType := d.Get("type").(string)
+	sizes, err := listVolumeSizes(ctx, c, volumeType)
+	if err != nil {
+		// Not every CloudAPI lists volume sizes, and it still checks the
+		// size when the volume is created, so the plan goes on without it.
+		// A volume whose size was rounded up is larger than the configured
+		// size, and is kept rather than replaced for not being rounded again.
+		log.Printf("[WARN] triton_volume: not checking size %d MiB, the sizes of %q volumes could not be listed: %s", want, volumeType, err)
+		if o, _ := d.GetChange("size"); d.Id() != "" && d.Get("size_rounding").(string) == volumeSizeRoundingUp && o.(int) > want {
+			log.Printf("[DEBUG] triton_volume: keeping size %d MiB, which %d MiB was rounded up to", o.(int), want)
+			return d.SetNew("size", o.(int))
 		}
+		return nil
+	}
+	if len(sizes) == 0 {
+		return fmt.Errorf("size %d MiB is not supported for %q volumes, CloudAPI lists no sizes for them", want, volumeType)
+	}
 
-		stateConf := &retry.StateChangeConf{
-			Pending: []string{oldName},
-			Target:  []string{newName},
-			Refresh: func() (interface{}, string, error) {
-				volume, err := c.Volumes().Get(context.Background(), &compute.GetVolumeInput{
-					ID: d.Id(),
-				})
-				if err != nil {
-					return nil, "", err
-				}
-
-				return volume, volume.Name, nil
-			},
-			Timeout:    *slowResourceTimeout.Update,
-			MinTimeout: defaultPollInterval,
+	supported := make([]string, 0, len(sizes))
+	for _, size := range sizes {
+		if size == want {
+			return nil
 		}
-		_, err = stateConf.WaitForState()
-		if err != nil {
-			return err
+		if size > want && d.Get("size_rounding").(string) == volumeSizeRoundingUp {
+			log.Printf("[DEBUG] triton_volume: rounding size %d up to %d MiB", want, size)
+			return d.SetNew("size", size)
 		}
+		supported = append(supported, strconv.Itoa(size))
 	}
 
-	d.Partial(false)
+	return fmt.Errorf("size %d MiB is not supported for %q volumes, supported sizes are: %s", want, volumeType, strings.Join(supported, ", "))
+}
 
-	return nil
+func resourceVolumeUpdate(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	c, err := client.Compute()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	// CloudAPI can only rename a volume; every other change replaces it.
+	if !d.HasChange("name") {
+		return resourceVolumeRead(d, meta)
+	}
+
+	name := d.Get("name").(string)
+	log.Printf("[DEBUG] triton_volume: renaming %q to %q", d.Id(), name)
+	err = c.Volumes().Update(ctx, &compute.UpdateVolumeInput{
+		ID:   d.Id(),
+		Name: name,
+	})
+	if err != nil {
+		return err
+	}
+
+	stateConf := &retry.StateChangeConf{
+		Target: []string{volumeStateReady},
+		Refresh: func() (interface{}, string, error) {
+			volume, err := c.Volumes().Get(ctx, &compute.GetVolumeInput{
+				ID: d.Id(),
+			})
+			if err != nil {
+				return nil, "", err
+			}
+			if volume.State == volumeStateFailed {
+				return nil, "", fmt.Errorf("volume update failed: %s", volume.State)
+			}
+			// CloudAPI may answer before the volume reflects the
+			// update, so a ready volume is only done once it does.
+			if volume.State == volumeStateReady && volume.Name != name {
+				return volume, "updating", nil
+			}
+
+			return volume, volume.State, nil
+		},
+		Timeout:    d.Timeout(schema.TimeoutUpdate),
+		MinTimeout: defaultPollInterval,
+	}
+	v, err := stateConf.WaitForState()
+	if err != nil {
+		return err
+	}
+
+	return tritonVolumeToTerraformVolume(d, v.(*compute.Volume))
 }
 
 func resourceVolumeDelete(d *schema.ResourceData, meta interface{}) error {
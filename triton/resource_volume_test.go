@@ -177,3 +177,160 @@ func testCheckTritonVolumeDestroy(s *terraform.State) error {
 
 	return nil
 }
+
+func TestFakeTritonVolume_update(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+
+	r := resourceVolume()
+	config := map[string]interface{}{
+		"name": "data",
+		"size": 10240,
+		"tags": map[string]interface{}{
+			"role": "db",
+		},
+	}
+
+	state, err := testFakeApply(r, nil, config, meta)
+	if err != nil {
+		t.Fatalf("error creating volume: %s", err)
+	}
+	id := state.ID
+
+	// The name is updated in place.
+	config["name"] = "data-renamed"
+	diff, err := testFakePlan(r, state, config, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff.RequiresNew() {
+		t.Fatalf("expected the volume to be renamed in place, got %#v", diff)
+	}
+	state, err = testFakeApply(r, state, config, meta)
+	if err != nil {
+		t.Fatalf("error updating volume: %s", err)
+	}
+	if state.ID != id || f.volumes[id].Name != "data-renamed" || state.Attributes["state"] != volumeStateReady {
+		t.Fatalf("unexpected volume after update: %#v", f.volumes[id])
+	}
+
+	diff, err = testFakePlan(r, state, config, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected an empty plan after update, got %#v", diff)
+	}
+
+	// CloudAPI can neither change the tags of a volume, resize it nor move
+	// it to other networks, so all of them replace the volume.
+	for _, change := range []map[string]interface{}{
+		{"tags": map[string]interface{}{"role": "cache"}},
+		{"size": 20480},
+		{"networks": []interface{}{fakePrivateNetworkID}},
+	} {
+		changed := map[string]interface{}{"name": config["name"], "size": config["size"], "tags": config["tags"]}
+		for k, v := range change {
+			changed[k] = v
+		}
+		diff, err = testFakePlan(r, state, changed, meta)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !diff.RequiresNew() {
+			t.Fatalf("expected %v to replace the volume, got %#v", change, diff)
+		}
+	}
+}
+
+func TestFakeTritonVolume_sizes(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+
+	r := resourceVolume()
+
+	_, err := testFakePlan(r, nil, map[string]interface{}{"size": 15000}, meta)
+	if err == nil || !strings.Contains(err.Error(), "size 15000 MiB is not supported") || !strings.Contains(err.Error(), "10240, 20480") {
+		t.Fatalf("expected an unsupported size to be rejected with the supported sizes, got %v", err)
+	}
+
+	config := map[string]interface{}{
+		"name":          "data",
+		"size":          15000,
+		"size_rounding": "up",
+	}
+	state, err := testFakeApply(r, nil, config, meta)
+	if err != nil {
+		t.Fatalf("error creating volume: %s", err)
+	}
+	if f.volumes[state.ID].Size != 20480 || state.Attributes["size"] != "20480" {
+		t.Fatalf("expected the size to be rounded up, got %v", state.Attributes)
+	}
+
+	diff, err := testFakePlan(r, state, config, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected an empty plan for a rounded size, got %#v", diff)
+	}
+
+	config["size"] = 1000000
+	if _, err := testFakePlan(r, state, config, meta); err == nil {
+		t.Fatal("expected a size larger than any supported size to be rejected")
+	}
+}
+
+func TestFakeTritonVolume_sizesUnavailable(t *testing.T) {
+	f := newFakeCloudAPI(t)
+	meta := f.Client(t)
+
+	r := resourceVolume()
+	config := map[string]interface{}{"size": 15000}
+	rounded := map[string]interface{}{"name": "data", "size": 15000, "size_rounding": "up"}
+	state, err := testFakeApply(r, nil, rounded, meta)
+	if err != nil {
+		t.Fatalf("error creating volume: %s", err)
+	}
+
+	// A CloudAPI without the volume sizes endpoint leaves the size to be
+	// checked when the volume is created.
+	routes := f.routes
+	f.routes = append([]fakeRoute{{
+		method:  http.MethodGet,
+		pattern: []string{"volumesizes"},
+		handler: func(w http.ResponseWriter, r *http.Request, _ []string) {
+			fakeError(w, http.StatusNotFound, "ResourceNotFound", "/volumesizes does not exist")
+		},
+	}}, routes...)
+	if _, err := testFakePlan(r, nil, config, meta); err != nil {
+		t.Fatalf("expected the size check to be skipped, got %s", err)
+	}
+
+	// The size a volume was rounded up to is kept rather than replaced,
+	// but a volume which is too small still is.
+	diff, err := testFakePlan(r, state, rounded, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("expected an empty plan for a rounded size, got %#v", diff)
+	}
+	rounded["size"] = 25000
+	diff, err = testFakePlan(r, state, rounded, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.RequiresNew() {
+		t.Fatalf("expected a larger size to replace the volume, got %#v", diff)
+	}
+	f.routes = routes
+
+	sizes := fakeVolumeSizes
+	fakeVolumeSizes = nil
+	defer func() { fakeVolumeSizes = sizes }()
+	_, err = testFakePlan(r, nil, config, meta)
+	if err == nil || !strings.Contains(err.Error(), "CloudAPI lists no sizes") {
+		t.Fatalf("expected an error for a type without sizes, got %v", err)
+	}
+}
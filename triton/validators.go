@@ -1,6 +1,10 @@
 package triton
 
-import "fmt"
+import (
+	"fmt"
+	"net"
+	"regexp"
+)
 
 // validateVLANIdentifier validates that the integer value is a valid VLAN ID,
 // which for the Fabric VLAN must be in the range between 0 and 4095 inclusive.
@@ -11,3 +15,30 @@ func validateVLANIdentifier(v interface{}, k string) (ws []string, errors []erro
 	}
 	return
 }
+
+// validateRegexp validates that the string value is a valid regular
+// expression.
+func validateRegexp(v interface{}, k string) (ws []string, errors []error) {
+	if _, err := regexp.Compile(v.(string)); err != nil {
+		errors = append(errors, fmt.Errorf("%q value must be a valid regular expression: %s", k, err))
+	}
+	return
+}
+
+// validateIPv4Address validates that the string value is an IPv4 address.
+func validateIPv4Address(v interface{}, k string) (ws []string, errors []error) {
+	if ip := net.ParseIP(v.(string)); ip == nil || ip.To4() == nil {
+		errors = append(errors, fmt.Errorf("%q value must be an IPv4 address, got %q", k, v.(string)))
+	}
+	return
+}
+
+// validateFirewallRule warns when the string value is not a firewall rule in
+// the subset of the CloudAPI rule syntax the provider understands. The rule
+// is still sent to CloudAPI as it is, which has the final say on it.
+func validateFirewallRule(v interface{}, k string) (ws []string, errors []error) {
+	if _, err := parseFWRule(v.(string)); err != nil {
+		ws = append(ws, fmt.Sprintf("%q value could not be checked and is passed to CloudAPI as it is: %s", k, err))
+	}
+	return
+}
@@ -42,3 +42,88 @@ func TestValidateVLANIdentifier(t *testing.T) {
 		t.Errorf("expected error to equal test error, got %s", e)
 	}
 }
+
+func TestValidateRegexp(t *testing.T) {
+	cases := []struct {
+		value  string
+		errors int
+	}{
+		{
+			value:  "^base-64-(lts|trunk)$",
+			errors: 0,
+		},
+		{
+			value:  "",
+			errors: 0,
+		},
+		{
+			value:  "base-64-(lts",
+			errors: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		_, errs := validateRegexp(tc.value, "name_regex")
+		if len(errs) != tc.errors {
+			t.Errorf("expected %d validation errors for value %q, got %d", tc.errors, tc.value, len(errs))
+		}
+	}
+}
+
+func TestValidateIPv4Address(t *testing.T) {
+	cases := []struct {
+		value  string
+		errors int
+	}{
+		{
+			value:  "192.168.128.10",
+			errors: 0,
+		},
+		{
+			value:  "2001:db8::1",
+			errors: 1,
+		},
+		{
+			value:  "192.168.128.0/24",
+			errors: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		_, errs := validateIPv4Address(tc.value, "ip")
+		if len(errs) != tc.errors {
+			t.Errorf("expected %d validation errors for value %q, got %d", tc.errors, tc.value, len(errs))
+		}
+	}
+}
+
+func TestValidateFirewallRule(t *testing.T) {
+	cases := []struct {
+		value    string
+		warnings int
+	}{
+		{
+			value:    `FROM any TO tag "www" ALLOW tcp PORT 80`,
+			warnings: 0,
+		},
+		{
+			value:    "FROM any TO all vms\n  ALLOW tcp (PORT 80 AND PORT 443)\n",
+			warnings: 0,
+		},
+		{
+			value:    `FROM any TO tag "www" ALLOW tcp`,
+			warnings: 1,
+		},
+		{
+			value:    `FROM any TO tag "www" PERMIT tcp PORT 80`,
+			warnings: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		ws, errs := validateFirewallRule(tc.value, "rule")
+		if len(ws) != tc.warnings || len(errs) != 0 {
+			t.Errorf("expected %d warnings and no errors for rule %q, got %v and %v", tc.warnings, tc.value, ws, errs)
+		}
+	}
+}